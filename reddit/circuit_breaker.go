@@ -0,0 +1,95 @@
+package reddit
+
+import (
+	"fmt"
+	"time"
+)
+
+// CircuitBreakerState is the state of a stream's circuit breaker.
+type CircuitBreakerState int
+
+const (
+	// CircuitClosed means fetches are being made normally.
+	CircuitClosed CircuitBreakerState = iota
+	// CircuitOpen means fetches are paused because too many of them failed in a row.
+	CircuitOpen
+	// CircuitHalfOpen means the cooldown has elapsed and a single probe fetch is being made.
+	CircuitHalfOpen
+)
+
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitBreakerState(%d)", int(s))
+	}
+}
+
+// CircuitBreakerEvent is sent into a stream's error channel whenever its circuit breaker changes state.
+// It isn't a failure in itself, so consumers that only care about fetch errors can ignore it.
+type CircuitBreakerEvent struct {
+	From CircuitBreakerState
+	To   CircuitBreakerState
+	// The number of consecutive failed fetches at the time of the change.
+	Failures int
+}
+
+func (e *CircuitBreakerEvent) Error() string {
+	return fmt.Sprintf("stream circuit breaker changed from %s to %s after %d consecutive failures", e.From, e.To, e.Failures)
+}
+
+// circuitBreaker pauses a stream's fetches after a run of consecutive failures.
+// It is only ever used from the stream's goroutine, so it doesn't need any locking.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	state    CircuitBreakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a fetch may be made at the given time.
+// Once the cooldown of an open breaker elapses, it half-opens and lets a single probe through.
+func (b *circuitBreaker) allow(now time.Time) (bool, *CircuitBreakerEvent) {
+	if b.state != CircuitOpen {
+		return true, nil
+	}
+	if now.Sub(b.openedAt) < b.cooldown {
+		return false, nil
+	}
+	return true, b.transition(CircuitHalfOpen)
+}
+
+// record registers the outcome of a fetch, returning an event if it caused the breaker to change state.
+func (b *circuitBreaker) record(err error, now time.Time) *CircuitBreakerEvent {
+	if err == nil {
+		b.failures = 0
+		if b.state == CircuitClosed {
+			return nil
+		}
+		return b.transition(CircuitClosed)
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.failures >= b.threshold) {
+		b.openedAt = now
+		return b.transition(CircuitOpen)
+	}
+	return nil
+}
+
+func (b *circuitBreaker) transition(to CircuitBreakerState) *CircuitBreakerEvent {
+	event := &CircuitBreakerEvent{From: b.state, To: to, Failures: b.failures}
+	b.state = to
+	return event
+}
@@ -0,0 +1,54 @@
+package reddit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	require.Equal(t, start, clock.Now())
+
+	ticker := clock.NewTicker(time.Second)
+
+	clock.Advance(time.Millisecond * 500)
+	require.Equal(t, start.Add(time.Millisecond*500), clock.Now())
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker fired before its interval elapsed")
+	default:
+	}
+
+	clock.Advance(time.Millisecond * 500)
+	require.Equal(t, start.Add(time.Second), <-ticker.C())
+
+	// ticks that aren't received in time are dropped, like with a *time.Ticker
+	clock.Advance(time.Second * 3)
+	require.Equal(t, start.Add(time.Second*2), <-ticker.C())
+	select {
+	case <-ticker.C():
+		t.Fatal("expected extra ticks to be dropped")
+	default:
+	}
+
+	ticker.Reset(time.Minute)
+	clock.Advance(time.Second * 59)
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker fired before its new interval elapsed")
+	default:
+	}
+	clock.Advance(time.Second)
+	require.Equal(t, start.Add(time.Second*4+time.Minute), <-ticker.C())
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker fired")
+	default:
+	}
+}
@@ -1,6 +1,7 @@
 package reddit
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -353,3 +354,53 @@ func TestCommentService_Report(t *testing.T) {
 	_, err := client.Comment.Report(ctx, "t1_test", "test reason")
 	require.NoError(t, err)
 }
+
+func TestComment_UnmarshalJSON_AlternateFieldNames(t *testing.T) {
+	comment := new(Comment)
+	err := json.Unmarshal([]byte(`{
+		"name": "t1_comment1",
+		"created": 1577836800,
+		"author_id": "t2_user1",
+		"over18": true
+	}`), comment)
+	require.NoError(t, err)
+	require.Equal(t, "t1_comment1", comment.FullID)
+	require.Equal(t, &Timestamp{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}, comment.Created)
+	require.Equal(t, "t2_user1", comment.AuthorID)
+	require.True(t, comment.NSFW)
+}
+
+func TestComment_ScoreHelpers(t *testing.T) {
+	comment := new(Comment)
+	err := json.Unmarshal([]byte(`{"name": "t1_comment1", "score": 1, "score_hidden": true, "controversiality": 1}`), comment)
+	require.NoError(t, err)
+	require.True(t, comment.IsScoreHidden())
+	require.True(t, comment.IsControversial())
+
+	comment = new(Comment)
+	err = json.Unmarshal([]byte(`{"name": "t1_comment2", "score": 1, "controversiality": 0}`), comment)
+	require.NoError(t, err)
+	require.False(t, comment.IsScoreHidden())
+	require.False(t, comment.IsControversial())
+
+	var none *Comment
+	require.False(t, none.IsScoreHidden())
+	require.False(t, none.IsControversial())
+}
+
+func TestComment_ContentHash(t *testing.T) {
+	comment := &Comment{FullID: "t1_a", Author: "user1", SubredditName: "test", PostID: "t3_a", ParentID: "t3_a", Body: "body", Score: 1}
+	hash := comment.ContentHash()
+
+	changed := *comment
+	changed.FullID = "t1_b"
+	changed.Score = 100
+	changed.Edited = &Timestamp{time.Now()}
+	require.Equal(t, hash, changed.ContentHash())
+
+	changed.Body = "body2"
+	require.NotEqual(t, hash, changed.ContentHash())
+	changed = *comment
+	changed.ParentID = "t1_c"
+	require.NotEqual(t, hash, changed.ContentHash())
+}
@@ -0,0 +1,35 @@
+package reddit
+
+// compactor buffers stream items, keeping only the most recent state of each item by its full ID.
+// Items are released in the order they were first buffered.
+type compactor[T Streamable] struct {
+	order []string
+	items map[string]T
+}
+
+func newCompactor[T Streamable]() *compactor[T] {
+	return &compactor[T]{items: make(map[string]T)}
+}
+
+func (c *compactor[T]) Add(item T) {
+	id := item.GetFullID()
+	if _, ok := c.items[id]; !ok {
+		c.order = append(c.order, id)
+	}
+	c.items[id] = item
+}
+
+func (c *compactor[T]) Len() int {
+	return len(c.order)
+}
+
+// Flush returns the buffered items and empties the buffer.
+func (c *compactor[T]) Flush() []T {
+	items := make([]T, 0, len(c.order))
+	for _, id := range c.order {
+		items = append(items, c.items[id])
+	}
+	c.order = nil
+	c.items = make(map[string]T)
+	return items
+}
@@ -0,0 +1,28 @@
+package reddit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactor(t *testing.T) {
+	c := newCompactor[*Post]()
+	require.Equal(t, 0, c.Len())
+	require.Empty(t, c.Flush())
+
+	c.Add(&Post{FullID: "t3_a", Score: 1})
+	c.Add(&Post{FullID: "t3_b", Score: 1})
+	c.Add(&Post{FullID: "t3_a", Score: 2})
+	c.Add(&Post{FullID: "t3_a", Score: 3})
+	require.Equal(t, 2, c.Len())
+
+	// items come out in the order they were first added, with their latest state
+	require.Equal(t, []*Post{
+		{FullID: "t3_a", Score: 3},
+		{FullID: "t3_b", Score: 1},
+	}, c.Flush())
+
+	require.Equal(t, 0, c.Len())
+	require.Empty(t, c.Flush())
+}
@@ -0,0 +1,63 @@
+package reddit
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DedupStore remembers the full IDs of the items a stream has seen, so that it doesn't emit them twice.
+// By default, streams use their HighWaterMark; use WithStreamDedupStore to replace it, e.g. with a store
+// shared between runs of a program.
+type DedupStore interface {
+	Contains(id string) bool
+	// Push records the ID, returning true if another one had to be forgotten to make room for it.
+	Push(id string) bool
+}
+
+// NewLRUDedupStore returns a DedupStore holding up to size IDs. Once it's full, it forgets about the least
+// recently used one: both recording an ID and finding it with Contains count as using it, so IDs that keep
+// showing up in the listing stick around. It is safe for concurrent use.
+func NewLRUDedupStore(size int) DedupStore {
+	return &lruDedupStore{size: size, order: list.New(), elements: make(map[string]*list.Element)}
+}
+
+type lruDedupStore struct {
+	mu   sync.Mutex
+	size int
+	// most recently used first
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+func (s *lruDedupStore) Contains(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.elements[id]
+	if ok {
+		s.order.MoveToFront(e)
+	}
+	return ok
+}
+
+func (s *lruDedupStore) Push(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size <= 0 {
+		return true
+	}
+	if e, ok := s.elements[id]; ok {
+		s.order.MoveToFront(e)
+		return false
+	}
+
+	s.elements[id] = s.order.PushFront(id)
+	if s.order.Len() <= s.size {
+		return false
+	}
+	oldest := s.order.Back()
+	s.order.Remove(oldest)
+	delete(s.elements, oldest.Value.(string))
+	return true
+}
@@ -0,0 +1,43 @@
+package reddit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUDedupStore(t *testing.T) {
+	store := NewLRUDedupStore(3)
+
+	require.False(t, store.Push("A"))
+	require.False(t, store.Push("B"))
+	require.False(t, store.Push("C"))
+
+	// A was pushed first, but it's been used since, so B is the least recently used
+	require.True(t, store.Contains("A"))
+	require.True(t, store.Push("D"))
+	require.False(t, store.Contains("B"))
+	require.True(t, store.Contains("A"))
+	require.True(t, store.Contains("C"))
+	require.True(t, store.Contains("D"))
+
+	// pushing an ID again uses it as well, without forgetting anything
+	require.False(t, store.Push("A"))
+	// the order is now, from the least recently used: C, D, A
+	require.True(t, store.Push("E"))
+	require.False(t, store.Contains("C"))
+	require.True(t, store.Push("F"))
+	require.False(t, store.Contains("D"))
+	require.True(t, store.Contains("A"))
+	require.True(t, store.Contains("E"))
+	require.True(t, store.Contains("F"))
+
+	// looking up an unknown ID doesn't change anything
+	require.False(t, store.Contains("G"))
+	require.True(t, store.Push("G"))
+	require.False(t, store.Contains("A"))
+
+	store0 := NewLRUDedupStore(0)
+	require.True(t, store0.Push("A"))
+	require.False(t, store0.Contains("A"))
+}
@@ -0,0 +1,28 @@
+package reddit
+
+import "fmt"
+
+// ErrorPolicy is what a stream does with an error when its errors channel is full.
+type ErrorPolicy int
+
+const (
+	// ErrorPolicyBlock waits for the error to be received, holding up the stream in the meantime.
+	ErrorPolicyBlock ErrorPolicy = iota
+	// ErrorPolicyDropOldest makes room for the error by dropping the oldest one in the channel.
+	ErrorPolicyDropOldest
+	// ErrorPolicyDropNewest drops the error, keeping the ones already in the channel.
+	ErrorPolicyDropNewest
+)
+
+func (p ErrorPolicy) String() string {
+	switch p {
+	case ErrorPolicyBlock:
+		return "block"
+	case ErrorPolicyDropOldest:
+		return "drop oldest"
+	case ErrorPolicyDropNewest:
+		return "drop newest"
+	default:
+		return fmt.Sprintf("ErrorPolicy(%d)", int(p))
+	}
+}
@@ -2,6 +2,7 @@ package reddit
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -64,6 +65,9 @@ type ErrorResponse struct {
 
 	// Error message
 	Message string `json:"message"`
+
+	// Reason is sometimes sent alongside the message, e.g. "banned" or "private" for subreddits.
+	Reason string `json:"reason,omitempty"`
 }
 
 func (r *ErrorResponse) Error() string {
@@ -73,6 +77,169 @@ func (r *ErrorResponse) Error() string {
 	)
 }
 
+// ErrSubredditNotFound is sent into a stream's error channel when Reddit reports that the subreddit
+// being streamed doesn't exist or has been banned. The stream stops right after sending it,
+// since retrying would never succeed.
+var ErrSubredditNotFound = errors.New("subreddit not found")
+
+// ErrInvalidStreamConfig is sent into a stream's error channel when the options it was given conflict with each other,
+// such as one that would be ignored because of another.
+// The stream stops right after sending it, without making any requests.
+var ErrInvalidStreamConfig = errors.New("invalid stream config")
+
+// ErrStreamCompleted is the StreamController's Err once its stream ended on its own, after
+// reaching its max requests or its max consecutive empty fetches.
+var ErrStreamCompleted = errors.New("stream completed")
+
+// ErrStreamStopped is the StreamController's Err once its stream ended because its stop function was called.
+var ErrStreamStopped = errors.New("stream stopped")
+
+// ErrStreamCancelled is wrapped by the StreamController's Err once its stream ended because its context is done.
+var ErrStreamCancelled = errors.New("stream cancelled")
+
+// StreamError is sent into a stream's error channel when it fails to fetch, or isn't configured properly.
+// If it's fatal, retrying would never succeed, e.g. because the subreddit doesn't exist or the client isn't
+// authorized to access it, so the stream stops right after sending it.
+type StreamError struct {
+	Err   error
+	Fatal bool
+}
+
+func (e *StreamError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StreamError) Unwrap() error {
+	return e.Err
+}
+
+// IsFatalStreamError reports whether err is a *StreamError that stopped its stream.
+// Such a stream won't get any further by being restarted with the same options.
+func IsFatalStreamError(err error) bool {
+	var streamErr *StreamError
+	return errors.As(err, &streamErr) && streamErr.Fatal
+}
+
+// newStreamError wraps an error returned when fetching the listing of a stream,
+// telling whether it's fatal. Errors about the subreddit not being found wrap ErrSubredditNotFound.
+func newStreamError(err error, subreddit string) *StreamError {
+	if reason, ok := subredditNotFound(err, subreddit); ok {
+		return &StreamError{Err: fmt.Errorf("%w: r/%s %s", ErrSubredditNotFound, subreddit, reason), Fatal: true}
+	}
+	return &StreamError{Err: err, Fatal: fatal(err)}
+}
+
+// fatal reports whether err would come back every time the request is made again:
+// what was requested not being found, or the client not being authorized to make it.
+// Rate limits and server errors aren't fatal.
+func fatal(err error) bool {
+	if errors.As(err, new(*InsufficientScopeError)) {
+		return true
+	}
+	var jsonErr *JSONErrorResponse
+	if errors.As(err, &jsonErr) {
+		for _, apiErr := range jsonErr.JSON.Errors {
+			if apiErr.Label == "SUBREDDIT_NOEXIST" {
+				return true
+			}
+		}
+		return false
+	}
+	var errResp *ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		switch errResp.Response.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+			return true
+		}
+	}
+	return false
+}
+
+// subredditNotFound reports whether err is Reddit telling us that the subreddit doesn't exist or is banned,
+// along with the reason it gave. A 404 without a reason only counts when it's for a listing of the subreddit,
+// since other endpoints, e.g. the inbox or mod notes, are also not found for reasons of their own.
+func subredditNotFound(err error, subreddit string) (string, bool) {
+	if subreddit == "" {
+		return "", false
+	}
+
+	var jsonErr *JSONErrorResponse
+	if errors.As(err, &jsonErr) {
+		for _, apiErr := range jsonErr.JSON.Errors {
+			if apiErr.Label == "SUBREDDIT_NOEXIST" {
+				return "does not exist", true
+			}
+		}
+		return "", false
+	}
+
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) {
+		return "", false
+	}
+	if errResp.Reason == "banned" {
+		return "banned", true
+	}
+	if errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound && subredditListing(errResp.Response.Request, subreddit) {
+		return "does not exist", true
+	}
+	return "", false
+}
+
+// subredditListing reports whether req is for one of the listings under r/subreddit.
+func subredditListing(req *http.Request, subreddit string) bool {
+	if req == nil || req.URL == nil {
+		return false
+	}
+	prefix := "/r/" + subreddit + "/"
+	return len(req.URL.Path) >= len(prefix) && strings.EqualFold(req.URL.Path[:len(prefix)], prefix)
+}
+
+// InsufficientScopeError occurs when the access token used by the client wasn't granted the OAuth scope
+// needed by the request. Retrying won't help until the app is authorized with that scope, so streams stop
+// right after sending it.
+type InsufficientScopeError struct {
+	// The scope required by the request, if Reddit said which one.
+	Scope string
+	// HTTP response that caused this error
+	Response *http.Response
+}
+
+func (e *InsufficientScopeError) Error() string {
+	scope := e.Scope
+	if scope == "" {
+		scope = "unknown"
+	}
+	return fmt.Sprintf(
+		"%s %s: %d insufficient scope (requires %s)",
+		e.Response.Request.Method, e.Response.Request.URL, e.Response.StatusCode, scope,
+	)
+}
+
+// insufficientScope checks the WWW-Authenticate header of a 403 response for an insufficient_scope error,
+// e.g. Bearer realm="reddit", error="insufficient_scope", scope="modlog", returning the scope it mentions.
+func insufficientScope(r *http.Response) (string, bool) {
+	if r.StatusCode != http.StatusForbidden {
+		return "", false
+	}
+	header := r.Header.Get("WWW-Authenticate")
+	if i := strings.IndexByte(header, ' '); i >= 0 {
+		header = header[i+1:]
+	}
+
+	params := make(map[string]string)
+	for _, param := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok {
+			params[strings.ToLower(key)] = strings.Trim(value, `"`)
+		}
+	}
+	if params["error"] != "insufficient_scope" {
+		return "", false
+	}
+	return params["scope"], true
+}
+
 // RateLimitError occurs when the client is sending too many requests to Reddit in a given time frame.
 type RateLimitError struct {
 	// Rate specifies the last known rate limit for the client
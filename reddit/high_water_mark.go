@@ -1,58 +1,242 @@
 package reddit
 
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
 func NewHighWaterMark(cap uint32, items ...string) HighWaterMark {
-	return &highWaterMark{marks: items, cap: cap}
+	return NewHighWaterMarkG(cap, items...)
+}
+
+// NewHighWaterMarkG returns a high water mark of any kind of keys, e.g. a struct of an ID and a count,
+// rather than having to concatenate them into a string.
+func NewHighWaterMarkG[T comparable](cap uint32, items ...T) HighWaterMarkG[T] {
+	h := &highWaterMark[T]{marks: items, cap: cap, counts: make(map[T]int, len(items))}
+	for _, item := range items {
+		h.counts[item]++
+	}
+	return h
+}
+
+// NewHighWaterMarkFromJSON restores a high water mark persisted with json.Marshal, along with its capacity.
+func NewHighWaterMarkFromJSON(data []byte) (HighWaterMark, error) {
+	h := new(highWaterMark[string])
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	return h, nil
 }
 
-type HighWaterMark interface {
+// SaveStreamState writes the high water mark of a stream to w as JSON, e.g. to a file on shutdown.
+// Restore it with LoadStreamState, and resume the stream from it with WithExistingHighWaterMark.
+func SaveStreamState(hwm HighWaterMark, w io.Writer) error {
+	if hwm == nil {
+		return errors.New("hwm: cannot be nil")
+	}
+	return json.NewEncoder(w).Encode(hwm)
+}
+
+// LoadStreamState reads a high water mark written with SaveStreamState from r, along with its capacity.
+func LoadStreamState(r io.Reader) (HighWaterMark, error) {
+	h := new(highWaterMark[string])
+	if err := json.NewDecoder(r).Decode(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// HighWaterMark is the high water mark of the full IDs of items, which is what streams use.
+type HighWaterMark = HighWaterMarkG[string]
+
+type HighWaterMarkG[T comparable] interface {
+	// Push adds the item as the most recent mark, and reports whether the oldest ones were dropped to make room for it.
+	Push(item T) bool
+	// PushUnique pushes the item, unless it's already one of the marks, so that the capacity only holds distinct items.
+	// It reports whether it was pushed, rather than whether older marks were dropped.
+	PushUnique(item T) bool
+	Contains(item T) bool
 	Len() int
-	Top() string
-	Push(item string) bool
-	Pop() string
+	// Top returns the most recent mark, or the zero value if there are none.
+	Top() T
+	// Pop removes the most recent mark and returns it, or the zero value if there are none.
+	Pop() T
+	// Items returns a copy of the marks, from the oldest to the most recent.
+	Items() []T
+	// Clear drops all of the marks, keeping the capacity.
+	Clear()
+	// Reset replaces all of the marks with the items, keeping the capacity.
+	// Like when constructing a mark, there can be more items than its capacity.
+	Reset(items ...T)
 }
 
 // Reddit is a crazy API. Using the before query param we're prone to failure because if you do ?before=id and id is deleted, we return no results
 // Reddit does not return every item that came "before" (but really, after) the item if the item ID sent is from a deleted record
 // So if we track the latest item, and the item gets deleted, we are perma-stuck querying no data. Which also means we can never recover
 // How's that for pain in the ass
-type highWaterMark struct {
-	marks []string
+//
+// The stream reads and writes the marks from its goroutine while the caller may be reading them, e.g. to persist them,
+// so they're guarded by a mutex. Methods must not call each other while holding it.
+type highWaterMark[T comparable] struct {
+	mu    sync.RWMutex
+	marks []T
 	cap   uint32
+	// How many times each item is in the marks, so that Contains doesn't have to go through all of them.
+	// The stream asks about every item it fetches, and there are a thousand marks by default
+	counts map[T]int
 }
 
-func (h *highWaterMark) Len() int {
+func (h *highWaterMark[T]) forget(items []T) {
+	for _, item := range items {
+		if h.counts[item]--; h.counts[item] <= 0 {
+			delete(h.counts, item)
+		}
+	}
+}
+
+func (h *highWaterMark[T]) Len() int {
 	if h == nil {
 		return 0
 	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
 	return len(h.marks)
 }
-func (h *highWaterMark) Top() string {
+func (h *highWaterMark[T]) Top() T {
+	var zero T
 	if h == nil {
-		return ""
+		return zero
 	}
-	return h.marks[h.Len()-1]
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.marks) == 0 {
+		return zero
+	}
+	return h.marks[len(h.marks)-1]
 }
-func (h *highWaterMark) Push(item string) bool {
+func (h *highWaterMark[T]) Push(item T) bool {
 	if h == nil {
 		panic("nil highWaterMark")
 	}
-	if uint32(h.Len()) == h.cap {
-		// Drop from the bottom, we want to keep things most recently seen
-		h.marks = h.marks[1:h.Len()]
-		h.marks = append(h.marks, item)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.push(item)
+}
+
+// push is Push, for when the mutex is already held.
+func (h *highWaterMark[T]) push(item T) bool {
+	if h.cap == 0 {
+		return true
+	}
+	if uint32(len(h.marks)) >= h.cap {
+		// Drop from the bottom, we want to keep things most recently seen.
+		// There can be more than cap marks if it was constructed with them
+		dropped := uint32(len(h.marks)) - h.cap + 1
+		h.forget(h.marks[:dropped])
+		h.marks = append(h.marks[dropped:], item)
+		h.counts[item]++
 		return true
 	}
 	h.marks = append(h.marks, item)
+	h.counts[item]++
 	return false
 }
-func (h *highWaterMark) Pop() string {
+func (h *highWaterMark[T]) PushUnique(item T) bool {
+	if h == nil {
+		panic("nil highWaterMark")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.counts[item] > 0 {
+		return false
+	}
+	h.push(item)
+	return true
+}
+func (h *highWaterMark[T]) Pop() T {
+	var zero T
 	if h == nil {
-		return ""
+		return zero
 	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if len(h.marks) == 0 {
-		return ""
+		return zero
 	}
-	item := h.marks[h.Len()-1]
-	h.marks = h.marks[:h.Len()-1]
+	item := h.marks[len(h.marks)-1]
+	h.marks = h.marks[:len(h.marks)-1]
+	h.forget([]T{item})
 	return item
 }
+
+func (h *highWaterMark[T]) Items() []T {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]T(nil), h.marks...)
+}
+
+func (h *highWaterMark[T]) Clear() {
+	h.Reset()
+}
+
+func (h *highWaterMark[T]) Reset(items ...T) {
+	if h == nil {
+		panic("nil highWaterMark")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.marks = append([]T(nil), items...)
+	h.counts = make(map[T]int, len(items))
+	for _, item := range items {
+		h.counts[item]++
+	}
+}
+
+func (h *highWaterMark[T]) Contains(item T) bool {
+	if h == nil {
+		return false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.counts[item] > 0
+}
+
+// highWaterMarkJSON is how a high water mark is persisted.
+type highWaterMarkJSON[T comparable] struct {
+	Marks []T    `json:"marks"`
+	Cap   uint32 `json:"cap"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (h *highWaterMark[T]) MarshalJSON() ([]byte, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	marks := h.marks
+	if marks == nil {
+		marks = []T{}
+	}
+	return json.Marshal(highWaterMarkJSON[T]{Marks: marks, Cap: h.cap})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (h *highWaterMark[T]) UnmarshalJSON(data []byte) error {
+	var v highWaterMarkJSON[T]
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.marks = v.Marks
+	h.cap = v.Cap
+	h.counts = make(map[T]int, len(v.Marks))
+	for _, item := range v.Marks {
+		h.counts[item]++
+	}
+	return nil
+}
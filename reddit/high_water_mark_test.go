@@ -1,6 +1,10 @@
 package reddit
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -97,6 +101,10 @@ func TestHighWaterMark_CapacityLimitsAndDropping(t *testing.T) {
 func TestHighWaterMark_EdgeCases(t *testing.T) {
 	hwm := NewHighWaterMark(2)
 
+	if top := hwm.Top(); top != "" {
+		t.Errorf("Expected empty string for the top of an empty stack, got '%s'", top)
+	}
+
 	if popped := hwm.Pop(); popped != "" {
 		t.Errorf("Expected empty string when popping from empty stack, got '%s'", popped)
 	}
@@ -118,6 +126,10 @@ func TestHighWaterMark_EdgeCases(t *testing.T) {
 		t.Errorf("Expected length 0 after popping single item, got %d", hwm.Len())
 	}
 
+	if top := hwm.Top(); top != "" {
+		t.Errorf("Expected empty string for the top once every item was popped, got '%s'", top)
+	}
+
 	hwm2 := NewHighWaterMark(1)
 	hwm2.Push("first")
 	hwm2.Push("second")
@@ -175,3 +187,293 @@ func TestHighWaterMark_ConstructorWithInitialItems(t *testing.T) {
 		t.Errorf("Expected to pop 'D' (kept after capacity enforcement), got '%s'", popped)
 	}
 }
+
+func TestHighWaterMark_Contains(t *testing.T) {
+	hwm := NewHighWaterMark(2, "A")
+
+	if !hwm.Contains("A") {
+		t.Error("Expected mark to contain initial item 'A'")
+	}
+	if hwm.Contains("B") {
+		t.Error("Expected mark not to contain 'B' before it is pushed")
+	}
+
+	hwm.Push("B")
+	hwm.Push("C")
+
+	if hwm.Contains("A") {
+		t.Error("Expected 'A' to be dropped once capacity was exceeded")
+	}
+	if !hwm.Contains("B") || !hwm.Contains("C") {
+		t.Error("Expected mark to contain 'B' and 'C'")
+	}
+
+	hwm0 := NewHighWaterMark(0)
+	if dropped := hwm0.Push("A"); !dropped {
+		t.Error("Expected Push to drop the item with capacity 0")
+	}
+	if hwm0.Contains("A") {
+		t.Error("Expected mark with capacity 0 to never contain anything")
+	}
+}
+
+func TestHighWaterMark_ContainsDuplicatesAndPops(t *testing.T) {
+	hwm := NewHighWaterMark(3, "A", "A", "B")
+
+	hwm.Push("C")
+	if !hwm.Contains("A") {
+		t.Error("Expected mark to still contain 'A' after only one of its copies was dropped")
+	}
+
+	hwm.Push("D")
+	if hwm.Contains("A") {
+		t.Error("Expected 'A' to be dropped once both of its copies were")
+	}
+
+	if popped := hwm.Pop(); popped != "D" {
+		t.Errorf("Expected to pop 'D', got '%s'", popped)
+	}
+	if hwm.Contains("D") {
+		t.Error("Expected mark not to contain 'D' once it was popped")
+	}
+	if !hwm.Contains("B") || !hwm.Contains("C") {
+		t.Error("Expected mark to contain 'B' and 'C'")
+	}
+}
+
+func TestHighWaterMark_ClearAndReset(t *testing.T) {
+	hwm := NewHighWaterMark(2, "A", "B")
+
+	hwm.Clear()
+	if hwm.Len() != 0 {
+		t.Errorf("Expected length 0 after clearing, got %d", hwm.Len())
+	}
+	if hwm.Contains("A") || hwm.Top() != "" {
+		t.Error("Expected cleared mark to contain nothing")
+	}
+
+	// the capacity is kept
+	hwm.Push("C")
+	hwm.Push("D")
+	hwm.Push("E")
+	if hwm.Len() != 2 {
+		t.Errorf("Expected length 2 with capacity 2 after clearing, got %d", hwm.Len())
+	}
+	if hwm.Contains("C") {
+		t.Error("Expected 'C' to be dropped once capacity was exceeded")
+	}
+
+	hwm.Reset("F", "G", "H")
+	if hwm.Len() != 3 {
+		t.Errorf("Expected length 3 after resetting with 3 items, got %d", hwm.Len())
+	}
+	if hwm.Contains("D") || hwm.Contains("E") {
+		t.Error("Expected reset mark not to contain its previous items")
+	}
+	if top := hwm.Top(); top != "H" {
+		t.Errorf("Expected top to be 'H', got '%s'", top)
+	}
+
+	// like when constructed with too many items, they're dropped from the bottom
+	hwm.Push("I")
+	if hwm.Len() != 2 {
+		t.Errorf("Expected length 2 with capacity 2 after resetting, got %d", hwm.Len())
+	}
+	if hwm.Contains("F") || hwm.Contains("G") || !hwm.Contains("H") || !hwm.Contains("I") {
+		t.Error("Expected mark to contain only 'H' and 'I'")
+	}
+}
+
+func TestHighWaterMark_JSON(t *testing.T) {
+	hwm := NewHighWaterMark(3, "A", "B")
+	hwm.Push("C")
+
+	data, err := json.Marshal(hwm)
+	if err != nil {
+		t.Fatalf("Expected no error marshaling, got %v", err)
+	}
+	if string(data) != `{"marks":["A","B","C"],"cap":3}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	restored, err := NewHighWaterMarkFromJSON(data)
+	if err != nil {
+		t.Fatalf("Expected no error restoring, got %v", err)
+	}
+	if restored.Len() != 3 || restored.Top() != "C" || !restored.Contains("A") {
+		t.Errorf("Expected restored mark to hold A, B and C, got length %d and top '%s'", restored.Len(), restored.Top())
+	}
+
+	// the capacity is restored as well
+	restored.Push("D")
+	if restored.Contains("A") {
+		t.Error("Expected 'A' to be dropped once capacity was exceeded")
+	}
+	if restored.Len() != 3 {
+		t.Errorf("Expected length 3, got %d", restored.Len())
+	}
+
+	empty, err := json.Marshal(NewHighWaterMark(5))
+	if err != nil {
+		t.Fatalf("Expected no error marshaling, got %v", err)
+	}
+	if string(empty) != `{"marks":[],"cap":5}` {
+		t.Errorf("Unexpected JSON: %s", empty)
+	}
+
+	if _, err := NewHighWaterMarkFromJSON([]byte(`{"marks": "A"}`)); err == nil {
+		t.Error("Expected an error restoring invalid JSON")
+	}
+}
+
+func TestHighWaterMarkG(t *testing.T) {
+	type key struct {
+		ID    string
+		Count int
+	}
+	hwm := NewHighWaterMarkG(2, key{"A", 1})
+
+	hwm.Push(key{"A", 2})
+	if !hwm.Contains(key{"A", 1}) || !hwm.Contains(key{"A", 2}) {
+		t.Error("Expected mark to contain both keys of 'A'")
+	}
+
+	hwm.Push(key{"B", 1})
+	if hwm.Contains(key{"A", 1}) {
+		t.Error("Expected the oldest key to be dropped once capacity was exceeded")
+	}
+
+	if popped := hwm.Pop(); popped != (key{"B", 1}) {
+		t.Errorf("Expected to pop {B 1}, got %v", popped)
+	}
+
+	hwm.Clear()
+	if top := hwm.Top(); top != (key{}) {
+		t.Errorf("Expected the zero key for the top of an empty mark, got %v", top)
+	}
+}
+
+func TestHighWaterMark_Items(t *testing.T) {
+	hwm := NewHighWaterMark(3, "A")
+	hwm.Push("B")
+	hwm.Push("C")
+	hwm.Push("D")
+
+	items := hwm.Items()
+	if fmt.Sprint(items) != "[B C D]" {
+		t.Errorf("Expected items [B C D], got %v", items)
+	}
+
+	items[0] = "X"
+	items = append(items, "Y")
+	if hwm.Contains("X") || hwm.Contains("Y") || !hwm.Contains("B") {
+		t.Error("Expected changes to the items not to affect the mark")
+	}
+	if fmt.Sprint(hwm.Items()) != "[B C D]" {
+		t.Errorf("Expected items [B C D], got %v", hwm.Items())
+	}
+
+	// pushing into the mark doesn't change a snapshot either
+	snapshot := hwm.Items()
+	hwm.Pop()
+	hwm.Push("E")
+	if fmt.Sprint(snapshot) != "[B C D]" {
+		t.Errorf("Expected snapshot [B C D], got %v", snapshot)
+	}
+
+	if items := NewHighWaterMark(3).Items(); len(items) != 0 {
+		t.Errorf("Expected no items for an empty mark, got %v", items)
+	}
+}
+
+func TestHighWaterMark_PushUnique(t *testing.T) {
+	hwm := NewHighWaterMark(3, "A", "B")
+
+	if pushed := hwm.PushUnique("C"); !pushed {
+		t.Error("Expected PushUnique to push an item that isn't a mark yet")
+	}
+	for i := 0; i < 5; i++ {
+		if pushed := hwm.PushUnique("C"); pushed {
+			t.Error("Expected PushUnique not to push an item that's already a mark")
+		}
+		if pushed := hwm.PushUnique("A"); pushed {
+			t.Error("Expected PushUnique not to push an item that's already a mark")
+		}
+	}
+
+	if fmt.Sprint(hwm.Items()) != "[A B C]" {
+		t.Errorf("Expected repeated pushes not to evict older items, got %v", hwm.Items())
+	}
+
+	// once the capacity is reached, distinct items still drop the oldest ones
+	hwm.PushUnique("D")
+	if fmt.Sprint(hwm.Items()) != "[B C D]" {
+		t.Errorf("Expected items [B C D], got %v", hwm.Items())
+	}
+}
+
+func TestHighWaterMark_Concurrent(t *testing.T) {
+	hwm := NewHighWaterMark(10, "A")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			hwm.Push(fmt.Sprintf("item%d", i))
+			if i%3 == 0 {
+				hwm.Pop()
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			// there's always at least one mark, since every pop follows a push
+			if top := hwm.Top(); top == "" {
+				t.Error("Expected top to never be empty")
+			}
+			if l := hwm.Len(); l < 1 || l > 10 {
+				t.Errorf("Expected length between 1 and 10, got %d", l)
+			}
+			hwm.Contains("A")
+		}
+	}()
+	wg.Wait()
+
+	// the last push filled it up, and was followed by a pop
+	if hwm.Len() != 9 {
+		t.Errorf("Expected length 9, got %d", hwm.Len())
+	}
+}
+
+func TestHighWaterMark_StreamState(t *testing.T) {
+	hwm := NewHighWaterMark(3, "t3_post1", "t3_post2")
+
+	var buf bytes.Buffer
+	if err := SaveStreamState(hwm, &buf); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+
+	restored, err := LoadStreamState(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error loading, got %v", err)
+	}
+	if restored.Len() != 2 || restored.Top() != "t3_post2" || !restored.Contains("t3_post1") {
+		t.Errorf("Expected restored mark to hold t3_post1 and t3_post2, got length %d and top '%s'", restored.Len(), restored.Top())
+	}
+
+	// the capacity is restored as well
+	restored.Push("t3_post3")
+	restored.Push("t3_post4")
+	if restored.Contains("t3_post1") || restored.Len() != 3 {
+		t.Errorf("Expected 't3_post1' to be dropped once capacity was exceeded, got length %d", restored.Len())
+	}
+
+	if err := SaveStreamState(nil, &buf); err == nil || err.Error() != "hwm: cannot be nil" {
+		t.Errorf("Expected an error saving a nil mark, got %v", err)
+	}
+	if _, err := LoadStreamState(bytes.NewBufferString("not json")); err == nil {
+		t.Error("Expected an error loading invalid JSON")
+	}
+}
@@ -0,0 +1,74 @@
+package reddit
+
+import "sync"
+
+// MergeErrors fans the errors received from chans, e.g. the errors channels of several streams, into a single
+// channel, which is closed once all of chans are. Nothing is left running once it's closed, so read it until
+// then; if you might stop reading it before, use MergeErrorsWithStop instead.
+func MergeErrors(chans ...<-chan error) <-chan error {
+	errs, _ := merge(chans)
+	return errs
+}
+
+// MergeErrorsWithStop is like MergeErrors, for the callers that stop reading the channel before it's closed.
+// Call the returned function when you do: from then on, the errors are dropped instead, so that the streams
+// behind chans don't get stuck sending errors nobody reads. chans keep being drained until they're closed, and
+// nothing is left running once they are.
+func MergeErrorsWithStop(chans ...<-chan error) (<-chan error, func()) {
+	return merge(chans)
+}
+
+// MergeStreams fans the items received from chans, e.g. the items channels of several streams, into a single
+// channel, which is closed once all of chans are. Items are sent in the order they're received, so items from
+// different streams can be interleaved. The streams don't know about each other: an item that shows up in more
+// than one of them, e.g. a post in both the new and rising listings, is sent once per stream. Deduplicating them
+// is up to the caller, e.g. with a HighWaterMark's PushUnique.
+// Like with MergeErrors, read the channel until it's closed, or use MergeStreamsWithStop instead.
+func MergeStreams[T Streamable](chans ...<-chan T) <-chan T {
+	items, _ := merge(chans)
+	return items
+}
+
+// MergeStreamsWithStop is like MergeStreams, for the callers that stop reading the channel before it's closed.
+// Like with MergeErrorsWithStop, call the returned function when you do.
+func MergeStreamsWithStop[T Streamable](chans ...<-chan T) (<-chan T, func()) {
+	return merge(chans)
+}
+
+// merge fans the items received from chans into a single channel, as described by MergeErrorsWithStop.
+func merge[T any](chans []<-chan T) (<-chan T, func()) {
+	out := make(chan T)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for item := range ch {
+				// once stopped, items are dropped even if someone happens to read out
+				select {
+				case <-done:
+					continue
+				default:
+				}
+				select {
+				case out <- item:
+				case <-done:
+				}
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+	return out, stop
+}
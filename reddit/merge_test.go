@@ -0,0 +1,171 @@
+package reddit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeErrors(t *testing.T) {
+	chans := make([]chan error, 3)
+	inputs := make([]<-chan error, len(chans))
+	for i := range chans {
+		chans[i] = make(chan error)
+		inputs[i] = chans[i]
+	}
+
+	merged := MergeErrors(inputs...)
+
+	for i, ch := range chans {
+		go func(i int, ch chan error) {
+			defer close(ch)
+			for j := 0; j < 2; j++ {
+				ch <- fmt.Errorf("stream %d: error %d", i, j)
+			}
+		}(i, ch)
+	}
+
+	// the channel is closed once all 3 inputs are
+	var received []string
+	for err := range merged {
+		received = append(received, err.Error())
+	}
+	require.ElementsMatch(t, []string{
+		"stream 0: error 0", "stream 0: error 1",
+		"stream 1: error 0", "stream 1: error 1",
+		"stream 2: error 0", "stream 2: error 1",
+	}, received)
+}
+
+func TestMergeErrorsWithStop(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	a, b := make(chan error), make(chan error)
+	merged, stop := MergeErrorsWithStop(a, b)
+
+	a <- errors.New("read")
+	require.EqualError(t, <-merged, "read")
+
+	// once stopped, the inputs are drained without anyone reading the merged channel
+	stop()
+	stop()
+	for i := 0; i < 3; i++ {
+		select {
+		case a <- errors.New("dropped"):
+		case <-time.After(time.Second):
+			t.Fatal("timed out sending an error after stopping")
+		}
+	}
+	close(a)
+	close(b)
+
+	select {
+	case _, ok := <-merged:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the merged channel to be closed")
+	}
+
+	// nothing is left running
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
+
+func TestMergeStreams(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post2"}},
+			{"kind": "t3", "data": {"name": "t3_post1"}}
+		]}}`)
+	})
+	mux.HandleFunc("/r/testsubreddit/rising", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post3"}},
+			{"kind": "t3", "data": {"name": "t3_post1"}}
+		]}}`)
+	})
+
+	newPosts, newErrs, stopNew := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+	)
+	defer stopNew()
+	risingPosts, risingErrs, stopRising := client.Stream.Rising(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+	)
+	defer stopRising()
+
+	posts := MergeStreams(newPosts, risingPosts)
+	errs := MergeErrors(newErrs, risingErrs)
+
+	// post1 is in both listings, so it's deduplicated here
+	seen := NewHighWaterMark(10)
+	var ids []string
+	for posts != nil || errs != nil {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				posts = nil
+				continue
+			}
+			if seen.PushUnique(post.FullID) {
+				ids = append(ids, post.FullID)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.ElementsMatch(t, []string{"t3_post1", "t3_post2", "t3_post3"}, ids)
+}
+
+func TestMergeStreamsWithStop(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	a, b, c := make(chan *Post), make(chan *Post), make(chan *Post)
+	merged, stop := MergeStreamsWithStop(a, b, c)
+
+	c <- &Post{FullID: "t3_post1"}
+	require.Equal(t, "t3_post1", (<-merged).FullID)
+
+	// once stopped, the inputs are drained without anyone reading the merged channel
+	stop()
+	for _, ch := range []chan *Post{a, b, c} {
+		select {
+		case ch <- &Post{FullID: "t3_dropped"}:
+		case <-time.After(time.Second):
+			t.Fatal("timed out sending a post after stopping")
+		}
+		close(ch)
+	}
+
+	select {
+	case _, ok := <-merged:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the merged channel to be closed")
+	}
+
+	// nothing is left running
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
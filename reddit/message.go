@@ -271,6 +271,15 @@ func (s *MessageService) InboxUnread(ctx context.Context, opts *ListOptions) ([]
 	return root.Comments, root.Messages, resp, nil
 }
 
+// Mentions returns the comments that mention your username, as they appear in your inbox.
+func (s *MessageService) Mentions(ctx context.Context, opts *ListOptions) ([]*Message, *Response, error) {
+	root, resp, err := s.inbox(ctx, "message/mentions", opts)
+	if err != nil {
+		return nil, resp, err
+	}
+	return root.Comments, resp, nil
+}
+
 // Sent returns messages that you've sent.
 func (s *MessageService) Sent(ctx context.Context, opts *ListOptions) ([]*Message, *Response, error) {
 	root, resp, err := s.inbox(ctx, "message/sent", opts)
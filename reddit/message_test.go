@@ -241,6 +241,22 @@ func TestMessageService_InboxUnread(t *testing.T) {
 	require.Equal(t, expectedMessages, messages)
 }
 
+func TestMessageService_Mentions(t *testing.T) {
+	client, mux := setup(t)
+
+	blob, err := readFileContents("../testdata/message/inbox.json")
+	require.NoError(t, err)
+
+	mux.HandleFunc("/message/mentions", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, blob)
+	})
+
+	mentions, _, err := client.Message.Mentions(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, expectedCommentMessages, mentions)
+}
+
 func TestMessageService_Sent(t *testing.T) {
 	client, mux := setup(t)
 
@@ -0,0 +1,177 @@
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// ModmailService handles communication with the modmail
+// related methods of the Reddit API.
+//
+// Reddit API docs: https://www.reddit.com/dev/api/#section_modmail
+type ModmailService struct {
+	client *Client
+}
+
+// Modmail conversation IDs are base36, e.g. "1a2b3c".
+var modmailConversationIDRegexp = regexp.MustCompile(`^[a-z0-9]+$`)
+
+func validateModmailConversationID(id string) error {
+	if !modmailConversationIDRegexp.MatchString(id) {
+		return fmt.Errorf("conversationID: %q is not a valid modmail conversation id", id)
+	}
+	return nil
+}
+
+// ModmailConversation is a conversation between the moderators of a subreddit and a user, or among the moderators.
+type ModmailConversation struct {
+	ID      string `json:"id,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	// The name of the subreddit the conversation belongs to.
+	Subreddit string `json:"-"`
+	// The ID of the most recent message in the conversation.
+	LastMessageID string `json:"-"`
+
+	NumMessages   int  `json:"numMessages"`
+	IsInternal    bool `json:"isInternal"`
+	IsHighlighted bool `json:"isHighlighted"`
+
+	LastUpdated    *Timestamp `json:"lastUpdated,omitempty"`
+	LastUserUpdate *Timestamp `json:"lastUserUpdate,omitempty"`
+	LastModUpdate  *Timestamp `json:"lastModUpdate,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *ModmailConversation) UnmarshalJSON(data []byte) error {
+	type conversation ModmailConversation
+	root := &struct {
+		*conversation
+		Owner struct {
+			DisplayName string `json:"displayName"`
+		} `json:"owner"`
+		// The messages and mod actions of the conversation, oldest first.
+		ObjIDs []struct {
+			ID  string `json:"id"`
+			Key string `json:"key"`
+		} `json:"objIds"`
+	}{conversation: (*conversation)(c)}
+
+	if err := json.Unmarshal(data, root); err != nil {
+		return err
+	}
+
+	c.Subreddit = root.Owner.DisplayName
+	for _, obj := range root.ObjIDs {
+		if obj.Key == "messages" {
+			c.LastMessageID = obj.ID
+		}
+	}
+	return nil
+}
+
+// GetFullID returns the ID of the conversation, along with the ID of its most recent message,
+// so that a conversation is streamed again every time a message is added to it.
+func (c *ModmailConversation) GetFullID() string {
+	return fmt.Sprintf("%s/%s", c.ID, c.LastMessageID)
+}
+
+// GetCreated returns the time of the last update to the conversation.
+func (c *ModmailConversation) GetCreated() *Timestamp {
+	return c.LastUpdated
+}
+
+// ListModmailConversationsOptions defines possible options used when getting modmail conversations.
+type ListModmailConversationsOptions struct {
+	// Maximum number of conversations to be returned.
+	// The default is 25 and max is 100.
+	Limit int `url:"limit,omitempty"`
+	// The ID of a conversation to use as the anchor point of the list.
+	// Only conversations appearing after it will be returned.
+	After string `url:"after,omitempty"`
+	// One of: recent, mod, user, unread.
+	Sort string `url:"sort,omitempty"`
+	// One of: all, new, inprogress, archived, appeals, join_requests, highlighted, mod, notifications.
+	State string `url:"state,omitempty"`
+}
+
+// Conversations gets the modmail conversations of the subreddit.
+// If the subreddit is empty, it gets the conversations of every subreddit you moderate.
+func (s *ModmailService) Conversations(ctx context.Context, subreddit string, opts *ListModmailConversationsOptions) ([]*ModmailConversation, *Response, error) {
+	path := "api/mod/conversations"
+	if subreddit != "" {
+		path += "?entity=" + url.QueryEscape(subreddit)
+	}
+	path, err := addOptions(path, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(struct {
+		Conversations   map[string]*ModmailConversation `json:"conversations"`
+		ConversationIDs []string                        `json:"conversationIds"`
+	})
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	conversations := make([]*ModmailConversation, 0, len(root.ConversationIDs))
+	for _, id := range root.ConversationIDs {
+		if conversation, ok := root.Conversations[id]; ok {
+			conversations = append(conversations, conversation)
+		}
+	}
+	return conversations, resp, nil
+}
+
+// Reply to a modmail conversation via its ID.
+// If internal is true, the reply is a private moderator note that the user won't see.
+func (s *ModmailService) Reply(ctx context.Context, conversationID string, body string, internal bool) (*Response, error) {
+	if err := validateModmailConversationID(conversationID); err != nil {
+		return nil, err
+	}
+	if body == "" {
+		return nil, errors.New("body: cannot be empty")
+	}
+
+	path := fmt.Sprintf("api/mod/conversations/%s", conversationID)
+
+	form := url.Values{}
+	form.Set("body", body)
+	form.Set("isAuthorHidden", "false")
+	form.Set("isInternal", strconv.FormatBool(internal))
+
+	req, err := s.client.NewRequest(http.MethodPost, path, form)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// Archive a modmail conversation via its ID.
+func (s *ModmailService) Archive(ctx context.Context, conversationID string) (*Response, error) {
+	if err := validateModmailConversationID(conversationID); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("api/mod/conversations/%s/archive", conversationID)
+
+	req, err := s.client.NewRequest(http.MethodPost, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
@@ -0,0 +1,104 @@
+package reddit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModmailService_Conversations(t *testing.T) {
+	client, mux := setup(t)
+
+	blob, err := readFileContents("../testdata/modmail/conversations.json")
+	require.NoError(t, err)
+
+	mux.HandleFunc("/api/mod/conversations", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+
+		params := url.Values{}
+		params.Set("entity", "testsubreddit")
+		params.Set("limit", "10")
+		params.Set("sort", "recent")
+		require.Equal(t, params, r.URL.Query())
+
+		fmt.Fprint(w, blob)
+	})
+
+	conversations, _, err := client.Modmail.Conversations(ctx, "testsubreddit", &ListModmailConversationsOptions{Limit: 10, Sort: "recent"})
+	require.NoError(t, err)
+	// the offsets of the timestamps are parsed into a location of their own
+	for _, c := range conversations {
+		for _, ts := range []*Timestamp{c.LastUpdated, c.LastUserUpdate, c.LastModUpdate} {
+			if ts != nil {
+				ts.Time = ts.UTC()
+			}
+		}
+	}
+	require.Equal(t, []*ModmailConversation{
+		{
+			ID:             "1abc2",
+			Subject:        "Why was my post removed?",
+			Subreddit:      "testsubreddit",
+			LastMessageID:  "2n0km",
+			NumMessages:    2,
+			IsHighlighted:  true,
+			LastUpdated:    &Timestamp{time.Date(2020, 1, 2, 15, 4, 5, 123456000, time.UTC)},
+			LastUserUpdate: &Timestamp{time.Date(2020, 1, 2, 15, 4, 5, 123456000, time.UTC)},
+		},
+		{
+			ID:            "1abc1",
+			Subject:       "Mod discussion",
+			Subreddit:     "testsubreddit",
+			LastMessageID: "2n0kk",
+			NumMessages:   1,
+			IsInternal:    true,
+			LastUpdated:   &Timestamp{time.Date(2020, 1, 1, 15, 4, 5, 0, time.UTC)},
+			LastModUpdate: &Timestamp{time.Date(2020, 1, 1, 15, 4, 5, 0, time.UTC)},
+		},
+	}, conversations)
+	require.Equal(t, "1abc2/2n0km", conversations[0].GetFullID())
+}
+
+func TestModmailService_Reply(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/api/mod/conversations/1abc2", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+
+		form := url.Values{}
+		form.Set("body", "thanks for reaching out")
+		form.Set("isAuthorHidden", "false")
+		form.Set("isInternal", "true")
+
+		err := r.ParseForm()
+		require.NoError(t, err)
+		require.Equal(t, form, r.PostForm)
+	})
+
+	_, err := client.Modmail.Reply(ctx, "1abc2", "thanks for reaching out", true)
+	require.NoError(t, err)
+
+	_, err = client.Modmail.Reply(ctx, "1abc2", "", false)
+	require.EqualError(t, err, "body: cannot be empty")
+
+	_, err = client.Modmail.Reply(ctx, "../1abc2", "hi", false)
+	require.EqualError(t, err, `conversationID: "../1abc2" is not a valid modmail conversation id`)
+}
+
+func TestModmailService_Archive(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/api/mod/conversations/1abc2/archive", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+	})
+
+	_, err := client.Modmail.Archive(ctx, "1abc2")
+	require.NoError(t, err)
+
+	_, err = client.Modmail.Archive(ctx, "")
+	require.EqualError(t, err, `conversationID: "" is not a valid modmail conversation id`)
+}
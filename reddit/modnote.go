@@ -2,8 +2,13 @@ package reddit
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type ModActionData struct {
@@ -34,6 +39,22 @@ type Modnote struct {
 	Type          string        `json:"type"`
 }
 
+// CreatedTime returns the time the note was created at.
+func (n *Modnote) CreatedTime() time.Time {
+	return time.Unix(int64(n.CreatedAt), 0).UTC()
+}
+
+func (n *Modnote) GetFullID() string {
+	return n.Id
+}
+
+func (n *Modnote) GetCreated() *Timestamp {
+	if n.CreatedAt == 0 {
+		return nil
+	}
+	return &Timestamp{n.CreatedTime()}
+}
+
 type notesList struct {
 	Modnotes []*Modnote `json:"mod_notes"`
 }
@@ -70,6 +91,59 @@ const (
 	ModnoteLabelStringHelpfulUser      ModnoteLabelString = "HELPFUL_USER"
 )
 
+// IsValid reports whether the filter is one of the ones Reddit knows about.
+func (f ModnoteFilterString) IsValid() bool {
+	switch f {
+	case ModnoteFilterStringNote, ModnoteFilterStringApproval, ModnoteFilterStringRemoval, ModnoteFilterStringBan,
+		ModnoteFilterStringMute, ModnoteFilterStringInvite, ModnoteFilterStringSpam, ModnoteFilterStringContentChange,
+		ModnoteFilterStringModAction, ModnoteFilterStringAll:
+		return true
+	}
+	return false
+}
+
+// IsValid reports whether the label is one of the ones Reddit knows about.
+func (l ModnoteLabelString) IsValid() bool {
+	switch l {
+	case ModnoteLabelStringBan, ModnoteLabelStringBotBan, ModnoteLabelStringPermaBan, ModnoteLabelStringAbuseWarning,
+		ModnoteLabelStringSpamWarning, ModnoteLabelStringSpamWatch, ModnoteLabelStringSolidContributor, ModnoteLabelStringHelpfulUser:
+		return true
+	}
+	return false
+}
+
+func (f ModnoteFilterString) String() string {
+	return string(f)
+}
+
+func (l ModnoteLabelString) String() string {
+	return string(l)
+}
+
+// modnoteEnumName turns a friendly name like "spam watch" or "spam-watch" into the form Reddit uses, "SPAM_WATCH".
+func modnoteEnumName(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(s)
+}
+
+// ParseModnoteFilter returns the filter with the given name, e.g. "note", "mod action" or "MOD_ACTION", regardless of its case.
+func ParseModnoteFilter(s string) (ModnoteFilterString, error) {
+	f := ModnoteFilterString(modnoteEnumName(s))
+	if !f.IsValid() {
+		return "", fmt.Errorf("filter: %q is not a valid modnote filter", s)
+	}
+	return f, nil
+}
+
+// ParseModnoteLabel returns the label with the given name, e.g. "spam watch", "spam_watch" or "HELPFUL_USER", regardless of its case.
+func ParseModnoteLabel(s string) (ModnoteLabelString, error) {
+	l := ModnoteLabelString(modnoteEnumName(s))
+	if !l.IsValid() {
+		return "", fmt.Errorf("label: %q is not a valid modnote label", s)
+	}
+	return l, nil
+}
+
 type GetModnotesForUserOptions struct {
 	// Before is an encoded pagination string. Notes have a "cursor" field that indicates what can go in this field
 	Before *string              `url:"before,omitempty"`
@@ -78,10 +152,21 @@ type GetModnotesForUserOptions struct {
 	Limit *int `url:"limit,omitempty"`
 }
 
+// GetModenotesForUser gets a page of the notes of the user.
+//
+// Deprecated: Use GetModnotesForUser instead.
 func (s *ModnoteService) GetModenotesForUser(ctx context.Context, subreddit string, user string, opts *GetModnotesForUserOptions) ([]*Modnote, *Response, error) {
+	return s.GetModnotesForUser(ctx, subreddit, user, opts)
+}
+
+// GetModnotesForUser gets a page of the notes of the user. Use the Cursor of the last note as the Before option to get the next page.
+func (s *ModnoteService) GetModnotesForUser(ctx context.Context, subreddit string, user string, opts *GetModnotesForUserOptions) ([]*Modnote, *Response, error) {
 	if opts == nil {
 		opts = &GetModnotesForUserOptions{}
 	}
+	if opts.Filter != nil && !opts.Filter.IsValid() {
+		return nil, nil, fmt.Errorf("filter: %q is not a valid modnote filter", *opts.Filter)
+	}
 	params := struct {
 		Limit     *int                 `url:"limit,omitempty"`
 		Filter    *ModnoteFilterString `url:"filter,omitempty"`
@@ -99,18 +184,158 @@ func (s *ModnoteService) GetModenotesForUser(ctx context.Context, subreddit stri
 	notes := &notesList{}
 	resp, err := s.client.doReqWithOptions(ctx, http.MethodGet, "api/mod/notes", &params, notes)
 	if err != nil {
-		return nil, nil, err
+		return nil, resp, err
 	}
 
 	return notes.Modnotes, resp, nil
 }
 
+type GetAllModnotesForUserOptions struct {
+	GetModnotesForUserOptions
+	// MaxPages caps the number of pages that are fetched. If 0 or less, pages are fetched until there are no more notes.
+	MaxPages int
+}
+
+// GetAllModnotesForUser gets the notes of the user, following the cursors of the notes from page to page until
+// there are no more of them. Pages are of 100 notes, unless a limit is set. The response is the one of the last page.
+func (s *ModnoteService) GetAllModnotesForUser(ctx context.Context, subreddit string, user string, opts *GetAllModnotesForUserOptions) ([]*Modnote, *Response, error) {
+	if opts == nil {
+		opts = &GetAllModnotesForUserOptions{}
+	}
+	pager := s.ModnotesForUserPager(ctx, subreddit, user, &opts.GetModnotesForUserOptions)
+
+	var all []*Modnote
+	for page := 0; !pager.Done() && (opts.MaxPages <= 0 || page < opts.MaxPages); page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, pager.Response(), err
+		}
+
+		notes, err := pager.Next(ctx)
+		if err != nil {
+			return nil, pager.Response(), err
+		}
+		all = append(all, notes...)
+	}
+
+	return all, pager.Response(), nil
+}
+
+// ModnotePager goes through the notes of a user one page at a time, following the cursors of the notes.
+// Create one with (*ModnoteService).ModnotesForUserPager.
+type ModnotePager struct {
+	ctx       context.Context
+	service   *ModnoteService
+	subreddit string
+	user      string
+	opts      GetModnotesForUserOptions
+	resp      *Response
+	done      bool
+}
+
+// ModnotesForUserPager returns a pager over the notes of the user, starting from the Before cursor of the options, if any.
+// Pages are of 100 notes, unless a limit is set. Once ctx is cancelled, Next stops getting pages and returns ctx.Err(),
+// so a single cancel stops the whole paging.
+func (s *ModnoteService) ModnotesForUserPager(ctx context.Context, subreddit string, user string, opts *GetModnotesForUserOptions) *ModnotePager {
+	p := &ModnotePager{ctx: ctx, service: s, subreddit: subreddit, user: user}
+	if opts != nil {
+		p.opts = *opts
+	}
+	if p.opts.Limit == nil {
+		limit := 100
+		p.opts.Limit = &limit
+	}
+	return p
+}
+
+// Next gets the next page of notes. Once there are no more of them, it returns nil, nil and Done reports true.
+// If getting the page fails, calling Next again retries it. The request is cancelled by either ctx or the one
+// of the pager.
+func (p *ModnotePager) Next(ctx context.Context) ([]*Modnote, error) {
+	if p.done {
+		return nil, nil
+	}
+	if err := p.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	notes, resp, err := p.service.GetModnotesForUser(ctx, p.subreddit, p.user, &p.opts)
+	if err != nil {
+		if pagerErr := p.ctx.Err(); pagerErr != nil {
+			return nil, pagerErr
+		}
+		return nil, err
+	}
+	p.resp = resp
+
+	if len(notes) == 0 {
+		p.done = true
+		return nil, nil
+	}
+	// a cursor that doesn't move means the same page came back again
+	cursor := notes[len(notes)-1].Cursor
+	if p.opts.Before != nil && *p.opts.Before == cursor {
+		p.done = true
+		return nil, nil
+	}
+	if cursor == "" {
+		p.done = true
+	}
+	p.opts.Before = &cursor
+	return notes, nil
+}
+
+// Done reports whether there are no more notes to get.
+func (p *ModnotePager) Done() bool {
+	return p.done
+}
+
+// Response returns the response of the last page that was fetched, or nil if none was.
+func (p *ModnotePager) Response() *Response {
+	return p.resp
+}
+
 type ModnoteUserSubredditPair struct {
 	Subreddit string
 	User      string
 }
 
+// The most pairs Reddit accepts in a single request for the most recent notes.
+const maxModnotePairs = 500
+
+// GetRecentModenotesForPairs gets the most recent note of each of the users in their subreddit.
+// Pairs are sent in batches of 500, which is the most Reddit accepts at once, and the notes of all
+// the batches are returned together. The response is the one of the last batch.
 func (s *ModnoteService) GetRecentModenotesForPairs(ctx context.Context, pairs []*ModnoteUserSubredditPair) ([]*Modnote, *Response, error) {
+	var all []*Modnote
+	var resp *Response
+	// without any pairs, the request is still made, to get Reddit's response to it
+	for start := 0; start == 0 || start < len(pairs); start += maxModnotePairs {
+		end := start + maxModnotePairs
+		if end > len(pairs) {
+			end = len(pairs)
+		}
+
+		notes, r, err := s.getRecentModnotesForPairs(ctx, pairs[start:end])
+		if err != nil {
+			return nil, r, err
+		}
+		all = append(all, notes...)
+		resp = r
+	}
+	return all, resp, nil
+}
+
+func (s *ModnoteService) getRecentModnotesForPairs(ctx context.Context, pairs []*ModnoteUserSubredditPair) ([]*Modnote, *Response, error) {
 	params := struct {
 		Subreddits string `url:"subreddits,omitempty"`
 		Users      string `url:"users,omitempty"`
@@ -127,7 +352,7 @@ func (s *ModnoteService) GetRecentModenotesForPairs(ctx context.Context, pairs [
 	notes := &notesList{}
 	resp, err := s.client.doReqWithOptions(ctx, http.MethodGet, "api/mod/notes/recent", &params, notes)
 	if err != nil {
-		return nil, nil, err
+		return nil, resp, err
 	}
 
 	return notes.Modnotes, resp, nil
@@ -151,23 +376,48 @@ func (s *ModnoteService) DeleteModnote(ctx context.Context, subreddit string, us
 	}{}
 	resp, err := s.client.doReqWithOptions(ctx, http.MethodDelete, "api/mod/notes", &params, deleted)
 	if err != nil {
-		return false, nil, err
+		return false, resp, err
 	}
 
 	return deleted.Deleted, resp, nil
 }
 
 type CreateModnoteOptions struct {
-	Label    *ModnoteLabelString
+	Label *ModnoteLabelString
+	// The full ID of the post or comment to link the note to, e.g. from ModnoteLinkToPost or ModnoteLinkToComment.
 	RedditID *string
 }
 
+// ModnoteLinkToPost returns the ID to set as CreateModnoteOptions.RedditID to link a note to the post.
+// It returns nil if the post is nil.
+func ModnoteLinkToPost(post *Post) *string {
+	if post == nil {
+		return nil
+	}
+	return String(post.FullID)
+}
+
+// ModnoteLinkToComment returns the ID to set as CreateModnoteOptions.RedditID to link a note to the comment.
+// It returns nil if the comment is nil.
+func ModnoteLinkToComment(comment *Comment) *string {
+	if comment == nil {
+		return nil
+	}
+	return String(comment.FullID)
+}
+
 // CreateNote creates a new modnote. Specify a t3_.. or t1_.. id in the reddit_id field if you want to link to a specific post or comment
 // Specify a label if you want to categorize the note.
 func (s *ModnoteService) CreateModnote(ctx context.Context, subreddit string, user string, message string, opts *CreateModnoteOptions) (*Modnote, *Response, error) {
 	if opts == nil {
 		opts = &CreateModnoteOptions{}
 	}
+	if opts.Label != nil && !opts.Label.IsValid() {
+		return nil, nil, fmt.Errorf("label: %q is not a valid modnote label", *opts.Label)
+	}
+	if opts.RedditID != nil && !strings.HasPrefix(*opts.RedditID, kindPost+"_") && !strings.HasPrefix(*opts.RedditID, kindComment+"_") {
+		return nil, nil, fmt.Errorf("RedditID: %q is not the full ID of a post or comment", *opts.RedditID)
+	}
 	params := struct {
 		Label     *ModnoteLabelString `url:"label,omitempty"`
 		User      string              `url:"user,omitempty"`
@@ -187,8 +437,105 @@ func (s *ModnoteService) CreateModnote(ctx context.Context, subreddit string, us
 	}{}
 	resp, err := s.client.doReqWithOptions(ctx, http.MethodPost, "api/mod/notes", &params, created)
 	if err != nil {
-		return nil, nil, err
+		return nil, resp, err
 	}
 
 	return created.Created, resp, nil
 }
+
+// The number of notes CreateModnotes creates at the same time.
+const createModnotesConcurrency = 4
+
+// CreateModnoteEntry is one of the notes to create with CreateModnotes.
+type CreateModnoteEntry struct {
+	User    string
+	Message string
+	Options *CreateModnoteOptions
+}
+
+// CreateModnotesError is returned by CreateModnotes when some of the notes couldn't be created.
+type CreateModnotesError struct {
+	// The errors of the entries that failed, by their index in the entries.
+	Errors map[int]error
+}
+
+func (e *CreateModnotesError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	msgs := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		msgs = append(msgs, fmt.Sprintf("entry %d: %s", i, e.Errors[i]))
+	}
+	return fmt.Sprintf("%d modnotes could not be created: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// CreateModnotes creates a note for each of the entries, a few at a time.
+// The notes that were created are returned in the order of their entries, even if some of the others couldn't be,
+// in which case the error is a *CreateModnotesError. The response is the one of the last entry that was created.
+func (s *ModnoteService) CreateModnotes(ctx context.Context, subreddit string, entries []CreateModnoteEntry) ([]*Modnote, *Response, error) {
+	notes := make([]*Modnote, len(entries))
+	resps := make([]*Response, len(entries))
+	errs := make([]error, len(entries))
+
+	sem := make(chan struct{}, createModnotesConcurrency)
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, entry CreateModnoteEntry) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			notes[i], resps[i], errs[i] = s.CreateModnote(ctx, subreddit, entry.User, entry.Message, entry.Options)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	created := make([]*Modnote, 0, len(entries))
+	var resp *Response
+	var failed map[int]error
+	for i, err := range errs {
+		if err != nil {
+			if failed == nil {
+				failed = make(map[int]error)
+			}
+			failed[i] = err
+			continue
+		}
+		created = append(created, notes[i])
+		resp = resps[i]
+	}
+
+	if failed != nil {
+		return created, resp, &CreateModnotesError{Errors: failed}
+	}
+	return created, resp, nil
+}
+
+// CreateNoteForAction creates a new modnote linked to the post or comment the mod action was taken on,
+// e.g. right after removing it. If user is empty, the author of the action's target is used.
+// The RedditID of the options is ignored, since it's derived from the action.
+func (s *ModnoteService) CreateNoteForAction(ctx context.Context, subreddit string, user string, action *ModAction, message string, opts *CreateModnoteOptions) (*Modnote, *Response, error) {
+	if action == nil {
+		return nil, nil, errors.New("*ModAction: cannot be nil")
+	}
+	if !strings.HasPrefix(action.TargetID, kindPost+"_") && !strings.HasPrefix(action.TargetID, kindComment+"_") {
+		return nil, nil, fmt.Errorf("(*ModAction).TargetID: %q is not the full ID of a post or comment", action.TargetID)
+	}
+	if user == "" {
+		user = action.TargetAuthor
+	}
+
+	linked := CreateModnoteOptions{}
+	if opts != nil {
+		linked = *opts
+	}
+	linked.RedditID = &action.TargetID
+
+	return s.CreateModnote(ctx, subreddit, user, message, &linked)
+}
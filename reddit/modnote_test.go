@@ -0,0 +1,478 @@
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModnoteService_CreateNoteForAction(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+
+		params := url.Values{}
+		params.Set("label", "SPAM_WARNING")
+		params.Set("user", "testuser")
+		params.Set("subreddit", "testsubreddit")
+		params.Set("note", "removed for spam")
+		params.Set("reddit_id", "t3_post1")
+		require.Equal(t, params, r.URL.Query())
+
+		fmt.Fprint(w, `{"created": {"id": "ModNote_123", "user": "testuser", "user_note_data": {"reddit_id": "t3_post1"}}}`)
+	})
+
+	action := &ModAction{
+		Action:       "removelink",
+		TargetAuthor: "testuser",
+		TargetID:     "t3_post1",
+	}
+
+	_, _, err := client.Modnotes.CreateNoteForAction(ctx, "testsubreddit", "", nil, "removed for spam", nil)
+	require.EqualError(t, err, "*ModAction: cannot be nil")
+
+	_, _, err = client.Modnotes.CreateNoteForAction(ctx, "testsubreddit", "", &ModAction{Action: "banuser", TargetID: "t2_user1"}, "removed for spam", nil)
+	require.EqualError(t, err, `(*ModAction).TargetID: "t2_user1" is not the full ID of a post or comment`)
+
+	label := ModnoteLabelStringSpamWarning
+	opts := &CreateModnoteOptions{Label: &label}
+	note, _, err := client.Modnotes.CreateNoteForAction(ctx, "testsubreddit", "", action, "removed for spam", opts)
+	require.NoError(t, err)
+	require.Equal(t, "ModNote_123", note.Id)
+	require.Equal(t, "t3_post1", *note.UserNoteData.RedditId)
+	require.Nil(t, opts.RedditID)
+}
+
+func TestModnoteService_GetAllModnotesForUser(t *testing.T) {
+	client, mux := setup(t)
+
+	pages := map[string]string{
+		"":        `{"mod_notes": [{"id": "ModNote_1", "cursor": "cursor1"}, {"id": "ModNote_2", "cursor": "cursor2"}]}`,
+		"cursor2": `{"mod_notes": [{"id": "ModNote_3", "cursor": "cursor3"}]}`,
+		"cursor3": `{"mod_notes": []}`,
+	}
+	var requests int
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		requests++
+
+		params := url.Values{}
+		params.Set("user", "testuser")
+		params.Set("subreddit", "testsubreddit")
+		params.Set("limit", "2")
+		params.Set("filter", "NOTE")
+		if before := r.URL.Query().Get("before"); before != "" {
+			params.Set("before", before)
+		}
+		require.Equal(t, params, r.URL.Query())
+
+		fmt.Fprint(w, pages[r.URL.Query().Get("before")])
+	})
+
+	limit := 2
+	filter := ModnoteFilterStringNote
+	opts := &GetAllModnotesForUserOptions{GetModnotesForUserOptions: GetModnotesForUserOptions{Limit: &limit, Filter: &filter}}
+	notes, resp, err := client.Modnotes.GetAllModnotesForUser(ctx, "testsubreddit", "testuser", opts)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	require.Equal(t, 3, requests)
+
+	var ids []string
+	for _, note := range notes {
+		ids = append(ids, note.Id)
+	}
+	require.Equal(t, []string{"ModNote_1", "ModNote_2", "ModNote_3"}, ids)
+	// the options passed in are left as they were
+	require.Nil(t, opts.Before)
+
+	requests = 0
+	opts.MaxPages = 1
+	notes, _, err = client.Modnotes.GetAllModnotesForUser(ctx, "testsubreddit", "testuser", opts)
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	require.Equal(t, 1, requests)
+}
+
+func TestModnoteService_GetAllModnotesForUser_StuckCursor(t *testing.T) {
+	client, mux := setup(t)
+
+	var requests int
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "100", r.URL.Query().Get("limit"))
+		// the same page keeps coming back
+		fmt.Fprint(w, `{"mod_notes": [{"id": "ModNote_1", "cursor": "cursor1"}]}`)
+	})
+
+	notes, _, err := client.Modnotes.GetAllModnotesForUser(ctx, "testsubreddit", "testuser", nil)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	require.Equal(t, 2, requests)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = client.Modnotes.GetAllModnotesForUser(canceled, "testsubreddit", "testuser", nil)
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 2, requests)
+}
+
+func TestModnoteService_ModnotesForUserPager(t *testing.T) {
+	client, mux := setup(t)
+
+	pages := map[string]string{
+		"":        `{"mod_notes": [{"id": "ModNote_1", "cursor": "cursor1"}, {"id": "ModNote_2", "cursor": "cursor2"}]}`,
+		"cursor2": `{"mod_notes": [{"id": "ModNote_3", "cursor": "cursor3"}]}`,
+		"cursor3": `{"mod_notes": []}`,
+	}
+	var requests int
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "testuser", r.URL.Query().Get("user"))
+		require.Equal(t, "testsubreddit", r.URL.Query().Get("subreddit"))
+		require.Equal(t, "100", r.URL.Query().Get("limit"))
+		requests++
+		fmt.Fprint(w, pages[r.URL.Query().Get("before")])
+	})
+
+	pager := client.Modnotes.ModnotesForUserPager(ctx, "testsubreddit", "testuser", nil)
+	require.False(t, pager.Done())
+	require.Nil(t, pager.Response())
+
+	notes, err := pager.Next(ctx)
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	require.Equal(t, "ModNote_1", notes[0].Id)
+	require.False(t, pager.Done())
+	require.NotNil(t, pager.Response())
+
+	notes, err = pager.Next(ctx)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	require.Equal(t, "ModNote_3", notes[0].Id)
+	require.False(t, pager.Done())
+
+	notes, err = pager.Next(ctx)
+	require.NoError(t, err)
+	require.Nil(t, notes)
+	require.True(t, pager.Done())
+
+	// once it's done, it doesn't make any more requests
+	notes, err = pager.Next(ctx)
+	require.NoError(t, err)
+	require.Nil(t, notes)
+	require.Equal(t, 3, requests)
+}
+
+func TestModnoteService_ModnotesForUserPager_Cancelled(t *testing.T) {
+	client, mux := setup(t)
+
+	pagerCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests int
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 2 {
+			// cancelling the pager mid request aborts it
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprintf(w, `{"mod_notes": [{"id": "ModNote_%d", "cursor": "cursor%d"}]}`, requests, requests)
+	})
+
+	pager := client.Modnotes.ModnotesForUserPager(pagerCtx, "testsubreddit", "testuser", nil)
+	notes, err := pager.Next(ctx)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+
+	_, err = pager.Next(ctx)
+	require.Equal(t, context.Canceled, err)
+
+	// it doesn't make any more requests once the pager's context is cancelled
+	_, err = pager.Next(ctx)
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 2, requests)
+	require.False(t, pager.Done())
+}
+
+func TestModnoteService_GetModnotesForUser(t *testing.T) {
+	client, mux := setup(t)
+
+	blob, err := readFileContents("../testdata/notes/get_modnotes.json")
+	require.NoError(t, err)
+
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+
+		params := url.Values{}
+		params.Set("user", "JewsOfHazard")
+		params.Set("subreddit", "notamod")
+		params.Set("before", "MTcwNjYwMjE2NzkzMA==")
+		require.Equal(t, params, r.URL.Query())
+
+		fmt.Fprint(w, blob)
+	})
+
+	before := "MTcwNjYwMjE2NzkzMA=="
+	opts := &GetModnotesForUserOptions{Before: &before}
+	notes, _, err := client.Modnotes.GetModnotesForUser(ctx, "notamod", "JewsOfHazard", opts)
+	require.NoError(t, err)
+	require.NotEmpty(t, notes)
+	require.Equal(t, "ModNote_e184ebe5-e149-457d-b383-47aa6133ded9", notes[0].Id)
+
+	// the misspelled name does the same
+	deprecated, _, err := client.Modnotes.GetModenotesForUser(ctx, "notamod", "JewsOfHazard", opts)
+	require.NoError(t, err)
+	require.Equal(t, notes, deprecated)
+}
+
+func TestModnote_CreatedTime(t *testing.T) {
+	var note Modnote
+	err := json.Unmarshal([]byte(`{"id": "ModNote_1", "created_at": 1706602167, "type": "NOTE"}`), &note)
+	require.NoError(t, err)
+	require.Equal(t, 1706602167, note.CreatedAt)
+	require.Equal(t, time.Date(2024, 1, 30, 8, 9, 27, 0, time.UTC), note.CreatedTime())
+}
+
+func TestModnoteService_CreateModnotes(t *testing.T) {
+	client, mux := setup(t)
+
+	var inFlight, maxInFlight int32
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "testsubreddit", r.URL.Query().Get("subreddit"))
+
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			peak := atomic.LoadInt32(&maxInFlight)
+			if n <= peak || atomic.CompareAndSwapInt32(&maxInFlight, peak, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 10)
+
+		user := r.URL.Query().Get("user")
+		if user == "baduser" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"message": "Bad Request", "error": 400}`)
+			return
+		}
+		fmt.Fprintf(w, `{"created": {"id": "ModNote_%s", "user": %q, "user_note_data": {"note": %q}}}`, user, user, r.URL.Query().Get("note"))
+	})
+
+	label := ModnoteLabelStringSpamWatch
+	var entries []CreateModnoteEntry
+	for i := 0; i < 10; i++ {
+		entries = append(entries, CreateModnoteEntry{User: fmt.Sprintf("user%d", i), Message: "part of the raid", Options: &CreateModnoteOptions{Label: &label}})
+	}
+	entries[3].User = "baduser"
+	entries[7].User = "baduser"
+
+	notes, resp, err := client.Modnotes.CreateModnotes(ctx, "testsubreddit", entries)
+	require.NotNil(t, resp)
+	require.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(4))
+
+	var batchErr *CreateModnotesError
+	require.True(t, errors.As(err, &batchErr))
+	require.Len(t, batchErr.Errors, 2)
+	require.Contains(t, batchErr.Errors, 3)
+	require.Contains(t, batchErr.Errors, 7)
+	require.True(t, strings.HasPrefix(err.Error(), "2 modnotes could not be created: entry 3: "))
+
+	var ids []string
+	for _, note := range notes {
+		ids = append(ids, note.Id)
+	}
+	require.Equal(t, []string{
+		"ModNote_user0", "ModNote_user1", "ModNote_user2", "ModNote_user4",
+		"ModNote_user5", "ModNote_user6", "ModNote_user8", "ModNote_user9",
+	}, ids)
+	require.Equal(t, "part of the raid", *notes[0].UserNoteData.Note)
+
+	notes, _, err = client.Modnotes.CreateModnotes(ctx, "testsubreddit", nil)
+	require.NoError(t, err)
+	require.Empty(t, notes)
+}
+
+func TestModnoteService_GetRecentModenotesForPairs_Batches(t *testing.T) {
+	client, mux := setup(t)
+
+	var batches [][]string
+	mux.HandleFunc("/api/mod/notes/recent", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+
+		users := strings.Split(r.URL.Query().Get("users"), ",")
+		subreddits := strings.Split(r.URL.Query().Get("subreddits"), ",")
+		require.Len(t, subreddits, len(users))
+		batches = append(batches, users)
+
+		var notes []string
+		for _, user := range users {
+			notes = append(notes, fmt.Sprintf(`{"id": "ModNote_%s", "user": %q}`, user, user))
+		}
+		fmt.Fprintf(w, `{"mod_notes": [%s]}`, strings.Join(notes, ","))
+	})
+
+	var pairs []*ModnoteUserSubredditPair
+	for i := 0; i < 600; i++ {
+		pairs = append(pairs, &ModnoteUserSubredditPair{Subreddit: "testsubreddit", User: fmt.Sprintf("user%d", i)})
+	}
+
+	notes, resp, err := client.Modnotes.GetRecentModenotesForPairs(ctx, pairs)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0], 500)
+	require.Len(t, batches[1], 100)
+	require.Equal(t, "user500", batches[1][0])
+
+	// the notes line up with the pairs
+	require.Len(t, notes, 600)
+	for i, note := range notes {
+		require.Equal(t, pairs[i].User, note.User)
+	}
+}
+
+func TestModnoteService_InvalidFilterAndLabel(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("no request should be made with an invalid filter or label")
+	})
+
+	filter := ModnoteFilterString("NOTES")
+	_, _, err := client.Modnotes.GetModnotesForUser(ctx, "testsubreddit", "testuser", &GetModnotesForUserOptions{Filter: &filter})
+	require.EqualError(t, err, `filter: "NOTES" is not a valid modnote filter`)
+
+	label := ModnoteLabelString("SPAM")
+	_, _, err = client.Modnotes.CreateModnote(ctx, "testsubreddit", "testuser", "spammer", &CreateModnoteOptions{Label: &label})
+	require.EqualError(t, err, `label: "SPAM" is not a valid modnote label`)
+}
+
+func TestParseModnoteLabel(t *testing.T) {
+	label, err := ParseModnoteLabel("spam_watch")
+	require.NoError(t, err)
+	require.Equal(t, ModnoteLabelStringSpamWatch, label)
+	require.True(t, label.IsValid())
+
+	label, err = ParseModnoteLabel("HELPFUL_USER")
+	require.NoError(t, err)
+	require.Equal(t, ModnoteLabelStringHelpfulUser, label)
+
+	label, err = ParseModnoteLabel(" helpful user ")
+	require.NoError(t, err)
+	require.Equal(t, ModnoteLabelStringHelpfulUser, label)
+
+	label, err = ParseModnoteLabel("Perma-Ban")
+	require.NoError(t, err)
+	require.Equal(t, ModnoteLabelStringPermaBan, label)
+
+	_, err = ParseModnoteLabel("helpfuluser")
+	require.EqualError(t, err, `label: "helpfuluser" is not a valid modnote label`)
+	require.False(t, ModnoteLabelString("").IsValid())
+
+	filter, err := ParseModnoteFilter("mod_action")
+	require.NoError(t, err)
+	require.Equal(t, ModnoteFilterStringModAction, filter)
+	require.True(t, ModnoteFilterStringAll.IsValid())
+
+	filter, err = ParseModnoteFilter("content change")
+	require.NoError(t, err)
+	require.Equal(t, ModnoteFilterStringContentChange, filter)
+
+	_, err = ParseModnoteFilter("")
+	require.EqualError(t, err, `filter: "" is not a valid modnote filter`)
+	_, err = ParseModnoteFilter("notes")
+	require.EqualError(t, err, `filter: "notes" is not a valid modnote filter`)
+}
+
+func TestModnoteEnums_RoundTrip(t *testing.T) {
+	labels := []ModnoteLabelString{
+		ModnoteLabelStringBan, ModnoteLabelStringBotBan, ModnoteLabelStringPermaBan, ModnoteLabelStringAbuseWarning,
+		ModnoteLabelStringSpamWarning, ModnoteLabelStringSpamWatch, ModnoteLabelStringSolidContributor, ModnoteLabelStringHelpfulUser,
+	}
+	for _, label := range labels {
+		parsed, err := ParseModnoteLabel(label.String())
+		require.NoError(t, err)
+		require.Equal(t, label, parsed)
+	}
+
+	filters := []ModnoteFilterString{
+		ModnoteFilterStringNote, ModnoteFilterStringApproval, ModnoteFilterStringRemoval, ModnoteFilterStringBan,
+		ModnoteFilterStringMute, ModnoteFilterStringInvite, ModnoteFilterStringSpam, ModnoteFilterStringContentChange,
+		ModnoteFilterStringModAction, ModnoteFilterStringAll,
+	}
+	for _, filter := range filters {
+		parsed, err := ParseModnoteFilter(strings.ToLower(filter.String()))
+		require.NoError(t, err)
+		require.Equal(t, filter, parsed)
+	}
+}
+
+func TestModnoteService_CreateModnote_LinkTo(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+
+		id := r.URL.Query().Get("reddit_id")
+		fmt.Fprintf(w, `{"created": {"id": "ModNote_123", "user_note_data": {"reddit_id": %q}}}`, id)
+	})
+
+	post := &Post{ID: "post1", FullID: "t3_post1"}
+	note, _, err := client.Modnotes.CreateModnote(ctx, "testsubreddit", "testuser", "see post", &CreateModnoteOptions{RedditID: ModnoteLinkToPost(post)})
+	require.NoError(t, err)
+	require.Equal(t, "t3_post1", *note.UserNoteData.RedditId)
+
+	comment := &Comment{ID: "comment1", FullID: "t1_comment1"}
+	note, _, err = client.Modnotes.CreateModnote(ctx, "testsubreddit", "testuser", "see comment", &CreateModnoteOptions{RedditID: ModnoteLinkToComment(comment)})
+	require.NoError(t, err)
+	require.Equal(t, "t1_comment1", *note.UserNoteData.RedditId)
+
+	require.Nil(t, ModnoteLinkToPost(nil))
+	require.Nil(t, ModnoteLinkToComment(nil))
+
+	_, _, err = client.Modnotes.CreateModnote(ctx, "testsubreddit", "testuser", "see post", &CreateModnoteOptions{RedditID: String("post1")})
+	require.EqualError(t, err, `RedditID: "post1" is not the full ID of a post or comment`)
+}
+
+func TestModnoteService_Rate(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRateLimitRemaining, "595.0")
+		w.Header().Set(headerRateLimitUsed, "5")
+		w.Header().Set(headerRateLimitReset, "120")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"mod_notes": []}`)
+	})
+
+	_, resp, err := client.Modnotes.GetModnotesForUser(ctx, "testsubreddit", "testuser", nil)
+	require.NoError(t, err)
+	require.Equal(t, 595, resp.Rate.Remaining)
+	require.Equal(t, 5, resp.Rate.Used)
+	require.WithinDuration(t, time.Now().Add(time.Minute*2), resp.Rate.Reset, time.Second*2)
+
+	// the rate is still available when the request fails, so that callers can back off
+	_, resp, err = client.Modnotes.CreateModnote(ctx, "testsubreddit", "testuser", "note", nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	require.Equal(t, 595, resp.Rate.Remaining)
+	require.Equal(t, 5, resp.Rate.Used)
+}
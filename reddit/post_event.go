@@ -0,0 +1,85 @@
+package reddit
+
+import "fmt"
+
+// PostEventKind is the kind of change a PostEvent describes.
+type PostEventKind int
+
+const (
+	// PostCreated means the post showed up in the subreddit's listing of new posts.
+	PostCreated PostEventKind = iota
+	// PostRemoved means the post was removed, e.g. by a moderator or by Reddit.
+	PostRemoved
+	// PostDeleted means the post was deleted by its author.
+	PostDeleted
+)
+
+func (k PostEventKind) String() string {
+	switch k {
+	case PostCreated:
+		return "created"
+	case PostRemoved:
+		return "removed"
+	case PostDeleted:
+		return "deleted"
+	default:
+		return fmt.Sprintf("PostEventKind(%d)", int(k))
+	}
+}
+
+// PostEvent is a change in the lifecycle of a post, as streamed by (*StreamService).PostLifecycle.
+type PostEvent struct {
+	Kind PostEventKind
+	// The state of the post when the change was noticed.
+	Post *Post
+}
+
+// GetFullID returns the full ID of the post, along with the kind of the event,
+// so that every kind of event is only streamed once per post.
+func (e *PostEvent) GetFullID() string {
+	return fmt.Sprintf("%s/%s", e.Post.FullID, e.Kind)
+}
+
+func (e *PostEvent) GetCreated() *Timestamp {
+	return e.Post.Created
+}
+
+// postEventKind returns the kind of event the current state of a post that was already streamed as created corresponds to.
+// Posts that are still up are reported as created.
+func postEventKind(post *Post) PostEventKind {
+	switch {
+	case post.RemovedByCategory == "deleted" || post.Author == "[deleted]":
+		return PostDeleted
+	case post.RemovedByCategory != "" || post.Body == "[removed]":
+		return PostRemoved
+	default:
+		return PostCreated
+	}
+}
+
+// recentPosts keeps track of the full IDs of the most recently created posts, up to a limit.
+type recentPosts struct {
+	limit int
+	ids   []string
+	known set
+}
+
+func newRecentPosts(limit int) *recentPosts {
+	return &recentPosts{limit: limit, known: set{}}
+}
+
+func (r *recentPosts) Add(id string) {
+	if r.known.Exists(id) {
+		return
+	}
+	r.known.Add(id)
+	r.ids = append(r.ids, id)
+	if len(r.ids) > r.limit {
+		r.known.Delete(r.ids[0])
+		r.ids = r.ids[1:]
+	}
+}
+
+func (r *recentPosts) IDs() []string {
+	return r.ids
+}
@@ -1,6 +1,7 @@
 package reddit
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -1133,3 +1134,120 @@ func TestPostService_Report(t *testing.T) {
 	_, err := client.Post.Report(ctx, "t3_test", "test reason")
 	require.NoError(t, err)
 }
+
+func TestPost_ContentCategories(t *testing.T) {
+	tests := map[string]struct {
+		json        string
+		nsfw        bool
+		spoiler     bool
+		contestMode bool
+		brandSafety string
+	}{
+		"safe": {
+			json:        `{"over_18": false, "spoiler": false, "contest_mode": false, "whitelist_status": "all_ads"}`,
+			brandSafety: "all_ads",
+		},
+		"nsfw": {
+			json:        `{"over_18": true, "spoiler": false, "contest_mode": false, "whitelist_status": "promo_adult_nsfw"}`,
+			nsfw:        true,
+			brandSafety: "promo_adult_nsfw",
+		},
+		"spoiler in contest mode": {
+			json:        `{"over_18": false, "spoiler": true, "contest_mode": true, "whitelist_status": "house_only"}`,
+			spoiler:     true,
+			contestMode: true,
+			brandSafety: "house_only",
+		},
+		"no whitelist status": {
+			json:    `{"over_18": true, "spoiler": true, "whitelist_status": null}`,
+			nsfw:    true,
+			spoiler: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			post := new(Post)
+			require.NoError(t, json.Unmarshal([]byte(test.json), post))
+			require.Equal(t, test.nsfw, post.IsNSFW())
+			require.Equal(t, test.spoiler, post.IsSpoiler())
+			require.Equal(t, test.contestMode, post.ContestMode)
+			require.Equal(t, test.brandSafety, post.BrandSafety())
+		})
+	}
+
+	var post *Post
+	require.False(t, post.IsNSFW())
+	require.False(t, post.IsSpoiler())
+	require.Empty(t, post.BrandSafety())
+}
+
+func TestPost_UnmarshalJSON_AlternateFieldNames(t *testing.T) {
+	post := new(Post)
+	err := json.Unmarshal([]byte(`{
+		"name": "t3_post1",
+		"created": 1577836800,
+		"author_id": "t2_user1",
+		"over18": true,
+		"comment_count": 12,
+		"flair_text": "Question"
+	}`), post)
+	require.NoError(t, err)
+	require.Equal(t, &Post{
+		FullID:           "t3_post1",
+		Created:          &Timestamp{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		AuthorID:         "t2_user1",
+		NSFW:             true,
+		NumberOfComments: 12,
+		LinkFlairText:    "Question",
+	}, post)
+
+	// the current names win when both are sent
+	post = new(Post)
+	err = json.Unmarshal([]byte(`{"num_comments": 3, "comment_count": 12, "over_18": false, "over18": true}`), post)
+	require.NoError(t, err)
+	require.Equal(t, 3, post.NumberOfComments)
+	require.False(t, post.NSFW)
+}
+
+func TestSortPostsByRatio(t *testing.T) {
+	posts := []*Post{
+		{FullID: "t3_a", UpvoteRatio: 0.5},
+		{FullID: "t3_b"},
+		{FullID: "t3_c", UpvoteRatio: 0.97},
+		{FullID: "t3_d", UpvoteRatio: 0.5},
+		{FullID: "t3_e", UpvoteRatio: 1},
+	}
+	SortPostsByRatio(posts)
+
+	var ids []string
+	for _, post := range posts {
+		ids = append(ids, post.FullID)
+	}
+	require.Equal(t, []string{"t3_e", "t3_c", "t3_a", "t3_d", "t3_b"}, ids)
+}
+
+func TestPost_ContentHash(t *testing.T) {
+	post := &Post{FullID: "t3_a", Author: "user1", SubredditName: "test", Title: "title", Body: "body", Score: 1, NumberOfComments: 1}
+	hash := post.ContentHash()
+	require.Len(t, hash, 64)
+
+	// volatile fields, and the ID, don't change it
+	changed := *post
+	changed.FullID = "t3_b"
+	changed.Score = 100
+	changed.NumberOfComments = 10
+	changed.Edited = &Timestamp{time.Now()}
+	require.Equal(t, hash, changed.ContentHash())
+
+	for name, other := range map[string]Post{
+		"author":    {Author: "user2", SubredditName: "test", Title: "title", Body: "body"},
+		"subreddit": {Author: "user1", SubredditName: "test2", Title: "title", Body: "body"},
+		"title":     {Author: "user1", SubredditName: "test", Title: "title2", Body: "body"},
+		"body":      {Author: "user1", SubredditName: "test", Title: "title", Body: "body2"},
+		"url":       {Author: "user1", SubredditName: "test", Title: "title", Body: "body", URL: "https://example.com"},
+		"shifted":   {Author: "user1", SubredditName: "test", Title: "titlebody"},
+	} {
+		require.NotEqual(t, hash, other.ContentHash(), name)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 )
 
 // Opt is used to further configure a client upon initialization.
@@ -55,6 +56,35 @@ func WithTokenURL(u string) Opt {
 	}
 }
 
+// WithRateLimitWait makes the client wait for the rate limit to reset when there are no requests left in
+// the current window, instead of failing the request with a *RateLimitError. The wait ends early with the
+// context's error if the context of the request is done first.
+func WithRateLimitWait() Opt {
+	return func(c *Client) error {
+		c.waitOnRateLimit = true
+		return nil
+	}
+}
+
+// WithRetry retries requests that get a 429 Too Many Requests or a 5xx response up to maxRetries times.
+// Before each retry, the client waits for as long as the response's Retry-After header says, or otherwise base
+// before the first retry and twice as long before each of the next ones, up to a minute. The wait ends early
+// with the context's error if the context of the request is done first.
+// Request bodies are buffered in memory when needed, so that they can be sent again.
+func WithRetry(maxRetries int, base time.Duration) Opt {
+	return func(c *Client) error {
+		if maxRetries <= 0 {
+			return errors.New("maxRetries: must be greater than 0")
+		}
+		if base <= 0 {
+			return errors.New("base: must be greater than 0")
+		}
+		c.maxRetries = maxRetries
+		c.retryBase = base
+		return nil
+	}
+}
+
 // FromEnv configures the client with values from environment variables.
 // Supported environment variables:
 // GO_REDDIT_CLIENT_ID to set the client's id.
@@ -37,6 +37,7 @@ const (
 	headerRateLimitRemaining = "x-ratelimit-remaining"
 	headerRateLimitUsed      = "x-ratelimit-used"
 	headerRateLimitReset     = "x-ratelimit-reset"
+	headerRetryAfter         = "Retry-After"
 )
 
 var defaultClient, _ = NewReadonlyClient()
@@ -69,6 +70,11 @@ type Client struct {
 
 	rateMu sync.Mutex
 	rate   Rate
+	// If set, requests wait for the rate limit to reset instead of failing once it's exhausted.
+	waitOnRateLimit bool
+	// If set, requests that get a 429 or 5xx response are retried up to maxRetries times.
+	maxRetries int
+	retryBase  time.Duration
 
 	ID       string
 	Secret   string
@@ -87,6 +93,7 @@ type Client struct {
 	Listings   *ListingsService
 	LiveThread *LiveThreadService
 	Message    *MessageService
+	Modmail    *ModmailService
 	Moderation *ModerationService
 	Multi      *MultiService
 	Post       *PostService
@@ -121,6 +128,7 @@ func newClient() *Client {
 	client.Listings = &ListingsService{client: client}
 	client.LiveThread = &LiveThreadService{client: client}
 	client.Message = &MessageService{client: client}
+	client.Modmail = &ModmailService{client: client}
 	client.Moderation = &ModerationService{client: client}
 	client.Multi = &MultiService{client: client}
 	client.Stream = &StreamService{client: client}
@@ -340,6 +348,11 @@ func parseRate(r *http.Response) Rate {
 // pointed to by v, or returned as an error if an API error has occurred. If v implements the io.Writer interface,
 // the raw response will be written to v, without attempting to decode it.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
+	if c.waitOnRateLimit {
+		if err := c.waitForRateLimitReset(ctx); err != nil {
+			return nil, err
+		}
+	}
 	if err := c.checkRateLimitBeforeDo(req); err != nil {
 		return &Response{
 			Response: err.Response,
@@ -347,6 +360,36 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 		}, err
 	}
 
+	if c.maxRetries == 0 {
+		return c.do(ctx, req, v)
+	}
+	if err := bufferRequestBody(req); err != nil {
+		return nil, err
+	}
+	for retry := 0; ; retry++ {
+		resp, err := c.do(ctx, req, v)
+		if retry >= c.maxRetries || resp == nil || !retryableStatus(resp.StatusCode) {
+			return resp, err
+		}
+
+		timer := time.NewTimer(c.retryWait(resp, retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return resp, err
+			}
+		}
+	}
+}
+
+// do sends the request once and handles its response, as described by Do.
+func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
 	resp, err := DoRequestWithClient(ctx, c.client, req)
 	if err != nil {
 		return nil, err
@@ -389,6 +432,76 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	return response, nil
 }
 
+// retryableStatus reports whether a request that got a response with the status code is worth retrying.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// retryWait returns how long to wait before retrying a request that got the response. It's the response's
+// Retry-After header if it has one, and otherwise the retry base doubled for each previous retry, up to a minute.
+func (c *Client) retryWait(resp *Response, retry int) time.Duration {
+	if after := resp.Header.Get(headerRetryAfter); after != "" {
+		if seconds, err := strconv.Atoi(after); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(after); err == nil {
+			return time.Until(at)
+		}
+	}
+
+	backoff := c.retryBase
+	for i := 0; i < retry && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// bufferRequestBody makes sure the body of the request can be sent again, by reading it into memory
+// if the request can't already provide a fresh copy of it.
+func bufferRequestBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// waitForRateLimitReset blocks until the rate limit resets if there are no requests left in the current window,
+// or until the context is done.
+func (c *Client) waitForRateLimitReset(ctx context.Context) error {
+	c.rateMu.Lock()
+	rate := c.rate
+	c.rateMu.Unlock()
+
+	if rate.Reset.IsZero() || rate.Remaining > 0 {
+		return nil
+	}
+	wait := time.Until(rate.Reset)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (c *Client) checkRateLimitBeforeDo(req *http.Request) *RateLimitError {
 	c.rateMu.Lock()
 	rate := c.rate
@@ -469,6 +582,10 @@ func CheckResponse(r *http.Response) error {
 		return nil
 	}
 
+	if scope, ok := insufficientScope(r); ok {
+		return &InsufficientScopeError{Scope: scope, Response: r}
+	}
+
 	errorResponse := &ErrorResponse{Response: r}
 	data, err = ioutil.ReadAll(r.Body)
 	if err == nil && len(data) > 0 {
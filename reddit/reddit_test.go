@@ -7,8 +7,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -69,6 +71,7 @@ func testClientServices(t *testing.T, c *Client) {
 		"Listings",
 		"LiveThread",
 		"Message",
+		"Modmail",
 		"Moderation",
 		"Multi",
 		"Post",
@@ -222,6 +225,166 @@ func TestClient_ErrorResponse(t *testing.T) {
 	require.Equal(t, http.StatusForbidden, resp.StatusCode)
 }
 
+func TestClient_Do_InsufficientScopeError(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/api/v1/test", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="reddit", error="insufficient_scope", scope="modlog"`)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Forbidden", "error": 403}`)
+	})
+	mux.HandleFunc("/api/v1/test2", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="reddit", error="invalid_token"`)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Forbidden", "error": 403}`)
+	})
+
+	req, err := client.NewRequest(http.MethodGet, "api/v1/test", nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(ctx, req, nil)
+	require.IsType(t, &InsufficientScopeError{}, err)
+	require.Equal(t, "modlog", err.(*InsufficientScopeError).Scope)
+	require.EqualError(t, err, fmt.Sprintf(`GET %s/api/v1/test: 403 insufficient scope (requires modlog)`, client.BaseURL))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// other authentication errors are regular error responses
+	req, err = client.NewRequest(http.MethodGet, "api/v1/test2", nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(ctx, req, nil)
+	require.IsType(t, &ErrorResponse{}, err)
+}
+
+func TestClient_Do_RateLimitWait(t *testing.T) {
+	client, mux := setup(t)
+	require.NoError(t, WithRateLimitWait()(client))
+
+	var counter int
+	mux.HandleFunc("/api/v1/test", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		counter++
+	})
+
+	req, err := client.NewRequest(http.MethodGet, "api/v1/test", nil)
+	require.NoError(t, err)
+
+	client.rate.Remaining = 0
+	client.rate.Reset = time.Now().Add(time.Millisecond * 200)
+
+	start := time.Now()
+	resp, err := client.Do(ctx, req, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 1, counter)
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Millisecond*200))
+
+	// a canceled context aborts the wait, without making the request
+	client.rate.Remaining = 0
+	client.rate.Reset = time.Now().Add(time.Minute)
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Millisecond*20)
+	defer cancel()
+	_, err = client.Do(waitCtx, req, nil)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Equal(t, 1, counter)
+}
+
+func TestClient_Do_Retry(t *testing.T) {
+	client, mux := setup(t)
+	require.NoError(t, WithRetry(3, time.Millisecond)(client))
+
+	var counter int
+	mux.HandleFunc("/api/v1/test", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "value", r.Form.Get("key"))
+
+		defer func() { counter++ }()
+		switch counter {
+		case 0:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 1:
+			w.Header().Set(headerRetryAfter, "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprint(w, `{"ok": true}`)
+		}
+	})
+
+	req, err := client.NewRequest(http.MethodPost, "api/v1/test", url.Values{"key": {"value"}})
+	require.NoError(t, err)
+
+	var root struct {
+		OK bool `json:"ok"`
+	}
+	start := time.Now()
+	resp, err := client.Do(ctx, req, &root)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.True(t, root.OK)
+	require.Equal(t, 3, counter)
+	// the Retry-After header of the 429 response is respected
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Second))
+
+	// a body that can't be rewound is buffered so that it's sent again too
+	counter = 0
+	req, err = http.NewRequest(http.MethodPost, client.BaseURL.String()+"/api/v1/test", ioutil.NopCloser(strings.NewReader("key=value")))
+	require.NoError(t, err)
+	req.Header.Set(headerContentType, mediaTypeForm)
+	_, err = client.Do(ctx, req, &root)
+	require.NoError(t, err)
+	require.Equal(t, 3, counter)
+}
+
+func TestClient_Do_RetryExhausted(t *testing.T) {
+	client, mux := setup(t)
+	require.NoError(t, WithRetry(2, time.Millisecond)(client))
+
+	var counter int
+	mux.HandleFunc("/api/v1/test", func(w http.ResponseWriter, r *http.Request) {
+		counter++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	mux.HandleFunc("/api/v1/notfound", func(w http.ResponseWriter, r *http.Request) {
+		counter++
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	req, err := client.NewRequest(http.MethodGet, "api/v1/test", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(ctx, req, nil)
+	require.IsType(t, &ErrorResponse{}, err)
+	require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	require.Equal(t, 3, counter)
+
+	// other errors aren't retried
+	counter = 0
+	req, err = client.NewRequest(http.MethodGet, "api/v1/notfound", nil)
+	require.NoError(t, err)
+	_, err = client.Do(ctx, req, nil)
+	require.IsType(t, &ErrorResponse{}, err)
+	require.Equal(t, 1, counter)
+
+	// a canceled context aborts the wait before a retry
+	require.NoError(t, WithRetry(2, time.Minute)(client))
+	counter = 0
+	req, err = client.NewRequest(http.MethodGet, "api/v1/test", nil)
+	require.NoError(t, err)
+	retryCtx, cancel := context.WithTimeout(ctx, time.Millisecond*20)
+	defer cancel()
+	_, err = client.Do(retryCtx, req, nil)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Equal(t, 1, counter)
+
+	_, err = NewClient(Credentials{}, WithRetry(0, time.Second))
+	require.EqualError(t, err, "maxRetries: must be greater than 0")
+	_, err = NewClient(Credentials{}, WithRetry(1, 0))
+	require.EqualError(t, err, "base: must be greater than 0")
+}
+
 func TestClient_Do_RateLimitError(t *testing.T) {
 	client, mux := setup(t)
 
@@ -1,20 +1,167 @@
 package reddit
 
-type set map[string]struct{}
+import (
+	"sort"
+	"sync"
+)
 
-func (s set) Add(v string) {
+type set = setG[string]
+
+// setG is a set of any kind of values, for keying on typed values rather than formatting them into strings.
+type setG[T comparable] map[T]struct{}
+
+// newSetFromSlice returns a set of the items, without their duplicates.
+func newSetFromSlice[T comparable](items []T) setG[T] {
+	s := make(setG[T], len(items))
+	for _, item := range items {
+		s.Add(item)
+	}
+	return s
+}
+
+func (s setG[T]) Add(v T) {
 	s[v] = struct{}{}
 }
 
-func (s set) Delete(v string) {
+func (s setG[T]) Delete(v T) {
 	delete(s, v)
 }
 
-func (s set) Len() int {
+func (s setG[T]) Len() int {
 	return len(s)
 }
 
-func (s set) Exists(v string) bool {
+func (s setG[T]) Exists(v T) bool {
 	_, ok := s[v]
 	return ok
 }
+
+// ToSlice returns the values of the set, in no particular order.
+func (s setG[T]) ToSlice() []T {
+	items := make([]T, 0, len(s))
+	for v := range s {
+		items = append(items, v)
+	}
+	return items
+}
+
+// ToSortedSlice returns the values of the set, sorted by less.
+func (s setG[T]) ToSortedSlice(less func(a, b T) bool) []T {
+	items := s.ToSlice()
+	sort.Slice(items, func(i, j int) bool {
+		return less(items[i], items[j])
+	})
+	return items
+}
+
+// Union returns a new set of the values in either set.
+func (s setG[T]) Union(other setG[T]) setG[T] {
+	union := make(setG[T], len(s)+len(other))
+	union.Merge(s)
+	union.Merge(other)
+	return union
+}
+
+// Intersect returns a new set of the values in both sets.
+func (s setG[T]) Intersect(other setG[T]) setG[T] {
+	smaller, larger := s, other
+	if len(larger) < len(smaller) {
+		smaller, larger = larger, smaller
+	}
+	intersection := make(setG[T])
+	for v := range smaller {
+		if larger.Exists(v) {
+			intersection.Add(v)
+		}
+	}
+	return intersection
+}
+
+// Difference returns a new set of the values in s that aren't in other.
+func (s setG[T]) Difference(other setG[T]) setG[T] {
+	difference := make(setG[T])
+	for v := range s {
+		if !other.Exists(v) {
+			difference.Add(v)
+		}
+	}
+	return difference
+}
+
+// Merge adds the values of other to s.
+func (s setG[T]) Merge(other setG[T]) {
+	for v := range other {
+		s.Add(v)
+	}
+}
+
+// lruSet is a set that holds up to a fixed number of values. Once it's full, adding a value
+// evicts the one that was added the longest time ago.
+type lruSet struct {
+	max int
+	// the values in the order they were added, as a ring once it's full: the oldest one is at next
+	order []string
+	next  int
+	known set
+}
+
+func newLRUSet(max int) *lruSet {
+	return &lruSet{max: max, order: make([]string, 0, max), known: set{}}
+}
+
+// Add adds the value, unless it's already in the set, in which case it keeps its place in the order.
+func (s *lruSet) Add(v string) {
+	if s.max <= 0 || s.known.Exists(v) {
+		return
+	}
+	if len(s.order) < s.max {
+		s.order = append(s.order, v)
+	} else {
+		s.known.Delete(s.order[s.next])
+		s.order[s.next] = v
+		s.next = (s.next + 1) % s.max
+	}
+	s.known.Add(v)
+}
+
+func (s *lruSet) Exists(v string) bool {
+	return s.known.Exists(v)
+}
+
+func (s *lruSet) Len() int {
+	return s.known.Len()
+}
+
+// syncSet is a set that is safe for concurrent use, for the ones shared between goroutines.
+type syncSet struct {
+	mu sync.RWMutex
+	s  set
+}
+
+func newSyncSet() *syncSet {
+	return &syncSet{s: set{}}
+}
+
+func (s *syncSet) Add(v string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Add(v)
+}
+
+func (s *syncSet) Delete(v string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Delete(v)
+}
+
+func (s *syncSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Len()
+}
+
+func (s *syncSet) Exists(v string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.s.Exists(v)
+}
@@ -0,0 +1,170 @@
+package reddit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetG(t *testing.T) {
+	ids := setG[string]{}
+	ids.Add("t3_post1")
+	ids.Add("t3_post2")
+	ids.Add("t3_post1")
+	require.Equal(t, 2, ids.Len())
+	require.True(t, ids.Exists("t3_post2"))
+	ids.Delete("t3_post2")
+	require.False(t, ids.Exists("t3_post2"))
+
+	// set is the same as a set of strings
+	var s set = ids
+	require.True(t, s.Exists("t3_post1"))
+
+	type report struct {
+		ID         string
+		NumReports int
+	}
+	reports := setG[report]{}
+	reports.Add(report{"t3_post1", 1})
+	reports.Add(report{"t3_post1", 2})
+	require.Equal(t, 2, reports.Len())
+	require.True(t, reports.Exists(report{"t3_post1", 2}))
+	require.False(t, reports.Exists(report{"t3_post1", 3}))
+	reports.Delete(report{"t3_post1", 1})
+	require.Equal(t, 1, reports.Len())
+
+	counts := setG[int]{}
+	counts.Add(1)
+	require.True(t, counts.Exists(1))
+	require.False(t, counts.Exists(0))
+}
+
+func TestSet_Algebra(t *testing.T) {
+	previous := set{"t3_post1": {}, "t3_post2": {}, "t3_post3": {}}
+	current := set{"t3_post2": {}, "t3_post3": {}, "t3_post4": {}}
+
+	require.Equal(t, set{"t3_post1": {}, "t3_post2": {}, "t3_post3": {}, "t3_post4": {}}, previous.Union(current))
+	require.Equal(t, set{"t3_post2": {}, "t3_post3": {}}, previous.Intersect(current))
+	require.Equal(t, set{"t3_post4": {}}, current.Difference(previous))
+	require.Equal(t, set{"t3_post1": {}}, previous.Difference(current))
+
+	// none of them change the sets themselves
+	require.Equal(t, 3, previous.Len())
+	require.Equal(t, 3, current.Len())
+
+	previous.Merge(current)
+	require.Equal(t, set{"t3_post1": {}, "t3_post2": {}, "t3_post3": {}, "t3_post4": {}}, previous)
+	require.Equal(t, 3, current.Len())
+}
+
+func TestSet_AlgebraEdgeCases(t *testing.T) {
+	a := set{"t3_post1": {}, "t3_post2": {}}
+	empty := set{}
+
+	require.Equal(t, a, a.Union(empty))
+	require.Equal(t, a, empty.Union(a))
+	require.Equal(t, set{}, empty.Union(empty))
+	require.Equal(t, set{}, a.Intersect(empty))
+	require.Equal(t, set{}, empty.Intersect(a))
+	require.Equal(t, a, a.Difference(empty))
+	require.Equal(t, set{}, empty.Difference(a))
+	require.Equal(t, set{}, a.Difference(a))
+
+	// a nil set behaves like an empty one
+	var none set
+	require.Equal(t, a, a.Union(none))
+	require.Equal(t, set{}, none.Intersect(a))
+	require.Equal(t, set{}, none.Difference(a))
+	empty.Merge(none)
+	require.Equal(t, 0, empty.Len())
+
+	disjoint := set{"t3_post3": {}, "t3_post4": {}}
+	require.Equal(t, 4, a.Union(disjoint).Len())
+	require.Equal(t, set{}, a.Intersect(disjoint))
+	require.Equal(t, a, a.Difference(disjoint))
+	require.Equal(t, disjoint, disjoint.Difference(a))
+}
+
+func TestSet_Slices(t *testing.T) {
+	s := newSetFromSlice([]string{"t3_post2", "t3_post1", "t3_post2", "t3_post3", "t3_post1"})
+	require.Equal(t, set{"t3_post1": {}, "t3_post2": {}, "t3_post3": {}}, s)
+
+	require.ElementsMatch(t, []string{"t3_post1", "t3_post2", "t3_post3"}, s.ToSlice())
+	require.Equal(t, []string{"t3_post1", "t3_post2", "t3_post3"}, s.ToSortedSlice(func(a, b string) bool { return a < b }))
+	require.Equal(t, []int{3, 2, 1}, newSetFromSlice([]int{1, 2, 3, 2}).ToSortedSlice(func(a, b int) bool { return a > b }))
+
+	require.Equal(t, set{}, newSetFromSlice[string](nil))
+	require.Empty(t, set{}.ToSlice())
+	require.NotNil(t, set{}.ToSlice())
+}
+
+func TestLRUSet(t *testing.T) {
+	s := newLRUSet(3)
+
+	for i := 1; i <= 10; i++ {
+		s.Add(fmt.Sprintf("t3_post%d", i))
+		require.LessOrEqual(t, s.Len(), 3)
+	}
+	require.Equal(t, 3, s.Len())
+
+	// the values are evicted in the order they were added
+	for i := 1; i <= 7; i++ {
+		require.False(t, s.Exists(fmt.Sprintf("t3_post%d", i)))
+	}
+	for i := 8; i <= 10; i++ {
+		require.True(t, s.Exists(fmt.Sprintf("t3_post%d", i)))
+	}
+
+	// adding a value again doesn't move it up, so it's still the next one to go
+	s.Add("t3_post8")
+	s.Add("t3_post11")
+	require.False(t, s.Exists("t3_post8"))
+	require.True(t, s.Exists("t3_post9"))
+	require.True(t, s.Exists("t3_post11"))
+	require.Equal(t, 3, s.Len())
+
+	none := newLRUSet(0)
+	none.Add("t3_post1")
+	require.False(t, none.Exists("t3_post1"))
+	require.Equal(t, 0, none.Len())
+}
+
+func TestSyncSet(t *testing.T) {
+	s := newSyncSet()
+	require.Equal(t, 0, s.Len())
+
+	s.Add("t3_post1")
+	s.Add("t3_post1")
+	require.Equal(t, 1, s.Len())
+	require.True(t, s.Exists("t3_post1"))
+
+	s.Delete("t3_post1")
+	require.False(t, s.Exists("t3_post1"))
+	require.Equal(t, 0, s.Len())
+}
+
+func TestSyncSet_Concurrent(t *testing.T) {
+	s := newSyncSet()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id := fmt.Sprintf("t3_post%d_%d", i, j)
+				s.Add(id)
+				s.Exists(id)
+				s.Len()
+				if j%2 == 0 {
+					s.Delete(id)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 200, s.Len())
+}
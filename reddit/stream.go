@@ -1,13 +1,24 @@
 package reddit
 
 import (
+	"container/list"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // StreamService allows streaming new content from Reddit as it appears.
+// When a stream ends, its channels are closed. To tell why, e.g. whether it reached its max requests or
+// failed for good, give it a StreamController: once the stream is done, its Err returns ErrStreamCompleted,
+// ErrStreamStopped, an error wrapping ErrStreamCancelled, or the fatal *StreamError that ended it.
 type StreamService struct {
 	client *Client
 }
@@ -20,10 +31,19 @@ var itemLimit = 100
 //   - a channel into which any errors will be sent
 //   - a function that the client can call once to stop the streaming and close the channels
 //
+// When ctx is cancelled, ctx.Err() is sent into the errors channel before the channels are closed,
+// unless nothing receives it within a second, so that a consumer that stopped reading doesn't keep the stream around.
+// That second is real time, even if the stream runs on a clock set with WithStreamClock.
+//
 // Because of the 100 post limit imposed by Reddit when fetching posts, some high-traffic
 // streams might drop submissions between API requests, such as when streaming r/all.
 func (s *StreamService) Posts(ctx context.Context, subreddit string, opts ...StreamOpt[*Post]) (<-chan *Post, <-chan error, func()) {
-	return doStream(ctx, subreddit, s.getPosts, opts...)
+	streamConfig := NewStreamConfig[*Post]()
+	for _, opt := range opts {
+		opt(streamConfig)
+	}
+	streamConfig.getAfter = s.getPostsAfter
+	return doStreamWithConfig(ctx, subreddit, s.getPosts, streamConfig)
 }
 
 func (s *StreamService) getPosts(ctx context.Context, subreddit string, beforeID string) ([]*Post, error) {
@@ -31,110 +51,420 @@ func (s *StreamService) getPosts(ctx context.Context, subreddit string, beforeID
 	return posts, err
 }
 
-// TODO: Generalize these two functions to have the same body... Maybe when generics is released ;)
-func (s *StreamService) Actions(ctx context.Context, subreddit string, opts ...StreamOpt[*ModAction]) (<-chan *ModAction, <-chan error, func()) {
-	return doStream(ctx, subreddit, s.getActions, opts...)
+func (s *StreamService) getPostsAfter(ctx context.Context, subreddit string, afterID string, limit int) ([]*Post, error) {
+	posts, _, err := s.client.Subreddit.NewPosts(ctx, subreddit, &ListOptions{Limit: limit, After: afterID})
+	return posts, err
 }
 
-func (s *StreamService) getActions(ctx context.Context, subreddit string, beforeID string) ([]*ModAction, error) {
-	posts, _, err := s.client.Moderation.Actions(ctx, subreddit, &ListModActionOptions{ListOptions: ListOptions{Limit: itemLimit, Before: beforeID}})
-	return posts, err
+// PostsInto streams posts from the specified subreddit like Posts, but sends them into the given channels
+// rather than into channels of its own, e.g. to feed an existing pipeline.
+// It returns a function that the client can call to stop the streaming. Once it returns, nothing else
+// is sent into the channels. The channels are owned by the caller: they're never closed by the stream,
+// including when it reaches its max requests, so it's up to the caller to close them once stopped.
+// Like with Posts, ctx.Err() is sent into errs when ctx is cancelled, unless nothing receives it within a second of real time.
+func (s *StreamService) PostsInto(ctx context.Context, subreddit string, out chan<- *Post, errs chan<- error, opts ...StreamOpt[*Post]) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	posts, streamErrs, _ := s.Posts(ctx, subreddit, opts...)
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		// keep draining the stream until it's done, even once stopped, so that it doesn't get stuck sending
+		var deadline time.Time
+		for posts != nil || streamErrs != nil {
+			select {
+			case post, ok := <-posts:
+				if !ok {
+					posts = nil
+					continue
+				}
+				select {
+				case out <- post:
+				case <-done:
+				case <-ctx.Done():
+				}
+			case err, ok := <-streamErrs:
+				if !ok {
+					streamErrs = nil
+					continue
+				}
+				sendWithinGrace(ctx, errs, done, err, &deadline)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+			<-finished
+		})
+	}
 }
 
-// TODO: Generalize these two functions to have the same body... Maybe when generics is released ;)
-// InboxUnread returns 3 channels, one for comments, DMs, and errors, in that order, plus a function to close the channel
-func (s *StreamService) InboxUnread(ctx context.Context, opts ...StreamOpt[*Message]) (<-chan *Message, <-chan *Message, <-chan error, func()) {
-	streamConfig := NewStreamConfig[*Message]()
+// Replay streams the posts of recorded listing responses, e.g. responses of r/{subreddit}/new saved to a file,
+// as if they were fetched one after the other by Posts: every tick of the stream goes through the next response,
+// skipping the posts that were already streamed. r holds the responses as a sequence of JSON values, e.g. one per line.
+// The responses are replayed as fast as possible, unless WithStreamInterval is given. The stream stops once they've
+// all been replayed; if one of them can't be decoded, its error is sent into the error channel in its stead.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Replay(ctx context.Context, r io.Reader, opts ...StreamOpt[*Post]) (<-chan *Post, <-chan error, func()) {
+	streamConfig := NewStreamConfig[*Post]()
+	streamConfig.Interval = time.Nanosecond
 	for _, opt := range opts {
 		opt(streamConfig)
 	}
 
-	ticker := time.NewTicker(streamConfig.Interval)
-	commentsCh := make(chan *Message)
-	dmsCh := make(chan *Message)
+	var pages [][]*Post
+	var decodeErr error
+	dec := json.NewDecoder(r)
+	for {
+		t := new(thing)
+		if err := dec.Decode(t); err != nil {
+			if err != io.EOF {
+				decodeErr = fmt.Errorf("replaying response %d: %w", len(pages)+1, err)
+			}
+			break
+		}
+		l, _ := t.Listing()
+		pages = append(pages, l.Posts())
+	}
+
+	requests := len(pages)
+	if decodeErr != nil || requests == 0 {
+		requests++
+	}
+	if streamConfig.MaxRequests == 0 || streamConfig.MaxRequests > requests {
+		streamConfig.MaxRequests = requests
+	}
+
+	var n int
+	getPosts := func(ctx context.Context, _ string, _ string) ([]*Post, error) {
+		defer func() { n++ }()
+		if n < len(pages) {
+			return pages[n], nil
+		}
+		return nil, decodeErr
+	}
+	return doStreamWithConfig(ctx, "", getPosts, streamConfig)
+}
+
+// MultiPosts streams posts from each of the specified subreddits, as if they were streamed separately with Posts,
+// merging them into a single channel of posts and a single channel of errors.
+// Unlike streaming "sub1+sub2" with Posts, a busy subreddit can't crowd out the posts of the others.
+// The options are applied to every one of the streams; stateful ones, such as WithStreamDedupStore, end up shared
+// between them. The source set with WithStreamRandSource only seeds a source of their own for each of the streams. A controller can only drive a single stream though, so WithStreamController is rejected:
+// a fatal *StreamError wrapping ErrInvalidStreamConfig is sent before the channels are closed.
+// The channels are closed once all the streams are done, or once the returned function is called.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) MultiPosts(ctx context.Context, subreddits []string, opts ...StreamOpt[*Post]) (<-chan *Post, <-chan error, func()) {
+	streamConfig := NewStreamConfig[*Post]()
+	for _, opt := range opts {
+		opt(streamConfig)
+	}
+	if controller := streamConfig.Controller; controller != nil {
+		postsCh := make(chan *Post)
+		errsCh := make(chan error, 1)
+		streamErr := &StreamError{Err: fmt.Errorf("%w: a controller can't drive the streams of several subreddits", ErrInvalidStreamConfig), Fatal: true}
+		errsCh <- streamErr
+		close(postsCh)
+		close(errsCh)
+		controller.end(streamErr)
+		controller.finish()
+		return postsCh, errsCh, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	postsCh := make(chan *Post)
 	errsCh := make(chan error)
 
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	stops := make([]func(), 0, len(subreddits))
+
+	var wg sync.WaitGroup
+	for _, subreddit := range subreddits {
+		// a rand.Source isn't safe for concurrent use, so every stream gets its own, seeded from the shared one
+		src := rand.NewSource(streamConfig.Rand.Int63())
+		posts, errs, stop := s.Posts(ctx, subreddit, append(opts[:len(opts):len(opts)], WithStreamRandSource[*Post](src))...)
+		stops = append(stops, stop)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// keep draining the stream until it's closed, even once stopped, so that it doesn't get stuck sending
+			var deadline time.Time
+			for posts != nil || errs != nil {
+				select {
+				case post, ok := <-posts:
+					if !ok {
+						posts = nil
+						continue
+					}
+					select {
+					case postsCh <- post:
+					case <-done:
+					case <-ctx.Done():
+					}
+				case err, ok := <-errs:
+					if !ok {
+						errs = nil
+						continue
+					}
+					sendWithinGrace(ctx, errsCh, done, err, &deadline)
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(postsCh)
+		close(errsCh)
+		close(finished)
+	}()
+
 	var once sync.Once
 	stop := func() {
 		once.Do(func() {
-			ticker.Stop()
-			close(commentsCh)
-			close(dmsCh)
-			close(errsCh)
+			close(done)
+			cancel()
+			for _, stop := range stops {
+				stop()
+			}
+			<-finished
 		})
 	}
+	return postsCh, errsCh, stop
+}
 
-	// originally used the "before" parameter, but if that post gets deleted, subsequent requests
-	// would just return empty listings; easier to just keep track of all post ids encountered
-	oldIDs := set{}
-	newIDs := set{}
+// TopPosts streams the top posts from the specified subreddit, emitting posts as they make it into the listing.
+// Use WithStreamTime to choose the time period the posts are ranked over; by default, Reddit uses the past day.
+// Since the listing is sorted by score, posts that were already streamed are skipped rather than marking the end of the new ones,
+// and every fetch requests the whole top of the listing.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) TopPosts(ctx context.Context, subreddit string, opts ...StreamOpt[*Post]) (<-chan *Post, <-chan error, func()) {
+	streamConfig := NewStreamConfig[*Post]()
+	for _, opt := range opts {
+		opt(streamConfig)
+	}
 
-	go func() {
-		defer stop()
+	getTopPosts := func(ctx context.Context, subreddit string, _ string) ([]*Post, error) {
+		return s.getTopPosts(ctx, subreddit, streamConfig.Time)
+	}
+	return doStreamWithConfig(ctx, subreddit, getTopPosts, streamConfig)
+}
 
-		var n int
-		infinite := streamConfig.MaxRequests == 0
+func (s *StreamService) getTopPosts(ctx context.Context, subreddit string, t string) ([]*Post, error) {
+	posts, _, err := s.client.Subreddit.TopPosts(ctx, subreddit, &ListPostOptions{ListOptions: ListOptions{Limit: itemLimit}, Time: t})
+	return posts, err
+}
 
-		for {
-			select {
-			case <-ctx.Done():
-				errsCh <- ctx.Err()
-				return
-			case <-ticker.C:
-			}
-			n++
+// CustomStream streams the items of a listing that StreamService has no dedicated stream for, e.g. a user's
+// saved posts, fetching them with get. It takes all the usual options. It's a function rather than a method of
+// StreamService, since methods can't have type parameters.
+//
+// get should return the newest page of the listing, newest first, requesting 100 items (Limit: 100) like
+// the other streams do; the stream itself keeps track of which items it already emitted. It's called with the
+// subreddit and a full ID that must be passed on as the "before" parameter of the listing. That ID is empty
+// unless WithDumbLogic is used, in which case it's the newest item of the previous page.
+//
+//	posts, errs, stop := reddit.CustomStream(ctx, "", func(ctx context.Context, _ string, before string) ([]*reddit.Post, error) {
+//		posts, _, _, err := client.User.Saved(ctx, &reddit.ListUserOverviewOptions{ListOptions: reddit.ListOptions{Limit: 100, Before: before}})
+//		return posts, err
+//	})
+func CustomStream[T Streamable](ctx context.Context, subreddit string, get func(ctx context.Context, subreddit string, beforeID string) ([]T, error), opts ...StreamOpt[T]) (<-chan T, <-chan error, func()) {
+	return doStream(ctx, subreddit, get, opts...)
+}
 
-			latest := Timestamp{time.Unix(0, 0)}
+// Rising streams the posts that are gaining traction in the specified subreddit, as they make it into its rising listing.
+// The listing is re-ordered all the time and the same post can stay in it across many fetches, so a post is only
+// streamed the first time it's seen there, and every fetch requests the whole listing.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Rising(ctx context.Context, subreddit string, opts ...StreamOpt[*Post]) (<-chan *Post, <-chan error, func()) {
+	return doStream(ctx, subreddit, s.getRising, opts...)
+}
 
-			messages, err := s.getInboxUnread(ctx, streamConfig.HighWaterMark.Pop())
-			if err != nil {
-				errsCh <- err
-				if !infinite && n >= streamConfig.MaxRequests {
-					break
-				}
-				continue
+func (s *StreamService) getRising(ctx context.Context, subreddit string, _ string) ([]*Post, error) {
+	posts, _, err := s.client.Subreddit.RisingPosts(ctx, subreddit, &ListOptions{Limit: itemLimit})
+	return posts, err
+}
+
+// PostLifecycle streams the lifecycle of the new posts from the specified subreddit:
+// an event is sent when a post shows up, and another one if it is later removed or deleted.
+// To notice those, every fetch also re-checks the most recent posts streamed so far (up to 100 of them).
+// The subreddit's listing is fetched from the top every time, so WithStartFromFullID has no effect.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) PostLifecycle(ctx context.Context, subreddit string, opts ...StreamOpt[*PostEvent]) (<-chan *PostEvent, <-chan error, func()) {
+	recent := newRecentPosts(itemLimit)
+	getPostEvents := func(ctx context.Context, subreddit string, _ string) ([]*PostEvent, error) {
+		return s.getPostEvents(ctx, subreddit, recent)
+	}
+	return doStream(ctx, subreddit, getPostEvents, opts...)
+}
+
+func (s *StreamService) getPostEvents(ctx context.Context, subreddit string, recent *recentPosts) ([]*PostEvent, error) {
+	var events []*PostEvent
+	if ids := recent.IDs(); len(ids) > 0 {
+		posts, _, err := s.client.Listings.GetPosts(ctx, ids...)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range posts {
+			if kind := postEventKind(post); kind != PostCreated {
+				events = append(events, &PostEvent{Kind: kind, Post: post})
 			}
+		}
+	}
 
-			for _, message := range messages {
-				id := message.ID
+	posts, _, err := s.client.Subreddit.NewPosts(ctx, subreddit, &ListOptions{Limit: itemLimit})
+	if err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		events = append(events, &PostEvent{Kind: PostCreated, Post: post})
+	}
+	// the listing is newest first, but the oldest posts should be the first ones to stop being re-checked
+	for i := len(posts) - 1; i >= 0; i-- {
+		recent.Add(posts[i].FullID)
+	}
+	return events, nil
+}
 
-				// if this message id is already part of the set, it means that it and the ones
-				// after it in the list have already been streamed, so break out of the loop
-				if newIDs.Exists(id) || oldIDs.Exists(id) {
-					break
-				}
-				newIDs.Add(id)
+// TODO: Generalize these two functions to have the same body... Maybe when generics is released ;)
+// Actions streams moderator actions from the specified subreddit.
+// Use WithStreamModerator to only stream the actions of a single moderator.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Actions(ctx context.Context, subreddit string, opts ...StreamOpt[*ModAction]) (<-chan *ModAction, <-chan error, func()) {
+	streamConfig := NewStreamConfig[*ModAction]()
+	for _, opt := range opts {
+		opt(streamConfig)
+	}
 
-				// If the new map is 10 times larger than item limit, make it the old map and clear it
-				if len(newIDs) >= itemLimit*10 {
-					oldIDs = newIDs
-					newIDs = make(map[string]struct{})
-				}
+	getActions := func(ctx context.Context, subreddit string, beforeID string) ([]*ModAction, error) {
+		return s.getActions(ctx, subreddit, streamConfig.Moderator, beforeID)
+	}
+	return doStreamWithConfig(ctx, subreddit, getActions, streamConfig)
+}
 
-				if streamConfig.DiscardInitial {
-					streamConfig.DiscardInitial = false
-					break
-				}
+func (s *StreamService) getActions(ctx context.Context, subreddit string, moderator string, beforeID string) ([]*ModAction, error) {
+	posts, _, err := s.client.Moderation.Actions(ctx, subreddit, &ListModActionOptions{ListOptions: ListOptions{Limit: itemLimit, Before: beforeID}, Moderator: moderator})
+	return posts, err
+}
 
-				if message.IsComment {
-					commentsCh <- message
-				} else {
-					dmsCh <- message
-				}
+// Unmoderated streams the posts of the specified subreddit that have yet to be approved or removed by a moderator.
+// Posts that get moderated and later show up in the listing again, e.g. once they're reported, aren't streamed again.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Unmoderated(ctx context.Context, subreddit string, opts ...StreamOpt[*Post]) (<-chan *Post, <-chan error, func()) {
+	return doStream(ctx, subreddit, s.getUnmoderated, opts...)
+}
 
-				if message.Created != nil && message.Created.After(latest.Time) {
-					latest = *message.Created
-					streamConfig.HighWaterMark.Push(message.FullID)
-				}
-			}
+func (s *StreamService) getUnmoderated(ctx context.Context, subreddit string, beforeID string) ([]*Post, error) {
+	posts, _, err := s.client.Moderation.Unmoderated(ctx, subreddit, &ListOptions{Limit: itemLimit, Before: beforeID})
+	return posts, err
+}
 
-			if !infinite && n >= streamConfig.MaxRequests {
-				break
+// Mentions streams the comments that mention your username, as they show up in your inbox.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Mentions(ctx context.Context, opts ...StreamOpt[*Message]) (<-chan *Message, <-chan error, func()) {
+	return doStream(ctx, "", s.getMentions, opts...)
+}
+
+func (s *StreamService) getMentions(ctx context.Context, _ string, beforeID string) ([]*Message, error) {
+	mentions, _, err := s.client.Message.Mentions(ctx, &ListOptions{Limit: itemLimit, Before: beforeID})
+	return mentions, err
+}
+
+// Modnotes streams the mod notes of the user in the specified subreddit as they are created,
+// including the ones added automatically for mod actions taken on the user.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Modnotes(ctx context.Context, subreddit string, user string, opts ...StreamOpt[*Modnote]) (<-chan *Modnote, <-chan error, func()) {
+	getModnotes := func(ctx context.Context, subreddit string, _ string) ([]*Modnote, error) {
+		return s.getModnotes(ctx, subreddit, user)
+	}
+	return doStream(ctx, subreddit, getModnotes, opts...)
+}
+
+func (s *StreamService) getModnotes(ctx context.Context, subreddit string, user string) ([]*Modnote, error) {
+	limit := itemLimit
+	notes, _, err := s.client.Modnotes.GetModnotesForUser(ctx, subreddit, user, &GetModnotesForUserOptions{Limit: &limit})
+	return notes, err
+}
+
+// Modmail streams the modmail conversations of the subreddit, most recently updated first.
+// A conversation is streamed when it's started, and again every time a message is added to it.
+// If the subreddit is empty, it streams the conversations of every subreddit you moderate.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Modmail(ctx context.Context, subreddit string, opts ...StreamOpt[*ModmailConversation]) (<-chan *ModmailConversation, <-chan error, func()) {
+	return doStream(ctx, subreddit, s.getModmail, opts...)
+}
+
+func (s *StreamService) getModmail(ctx context.Context, subreddit string, _ string) ([]*ModmailConversation, error) {
+	conversations, _, err := s.client.Modmail.Conversations(ctx, subreddit, &ListModmailConversationsOptions{Limit: itemLimit, Sort: "recent"})
+	return conversations, err
+}
+
+// InboxUnread returns 3 channels, one for comments, DMs, and errors, in that order, plus a function to close the channel
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) InboxUnread(ctx context.Context, opts ...StreamOpt[*Message]) (<-chan *Message, <-chan *Message, <-chan error, func()) {
+	return doInboxStream(ctx, s.getInboxUnread, s.client.Message.Read, opts)
+}
+
+// Inbox streams every message that arrives in the inbox, whether it has been read or not, so that items
+// marked as read elsewhere are still processed once. Like InboxUnread, it returns 3 channels, one for comments,
+// DMs, and errors, in that order, plus a function to close the channels.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Inbox(ctx context.Context, opts ...StreamOpt[*Message]) (<-chan *Message, <-chan *Message, <-chan error, func()) {
+	return doInboxStream(ctx, s.getInbox, s.client.Message.Read, opts)
+}
+
+// doInboxStream streams the messages returned by getter, splitting them into comment replies and DMs.
+// markRead is used to mark the streamed messages as read, if the stream was asked to.
+func doInboxStream(
+	ctx context.Context,
+	getter func(ctx context.Context, beforeID string) ([]*Message, error),
+	markRead func(ctx context.Context, ids ...string) (*Response, error),
+	opts []StreamOpt[*Message],
+) (<-chan *Message, <-chan *Message, <-chan error, func()) {
+	streamConfig := NewStreamConfig[*Message]()
+	for _, opt := range opts {
+		opt(streamConfig)
+	}
+
+	getMessages := func(ctx context.Context, _ string, beforeID string) ([]*Message, error) {
+		return getter(ctx, beforeID)
+	}
+	split := func(message *Message) (*Message, *Message, bool) {
+		return message, message, message.IsComment
+	}
+	var delivered func(ctx context.Context, message *Message) error
+	if streamConfig.MarkRead {
+		delivered = func(ctx context.Context, message *Message) error {
+			if _, err := markRead(ctx, message.FullID); err != nil {
+				streamConfig.log("error", "mark read failed", "id", message.FullID, "err", err)
+				return fmt.Errorf("marking %s as read: %w", message.FullID, err)
 			}
+			return nil
 		}
-	}()
-
-	return commentsCh, dmsCh, errsCh, stop
+	}
+	return doStream2(ctx, "", getMessages, streamConfig, split, delivered)
 }
 
 func (s *StreamService) getInboxUnread(ctx context.Context, beforeID string) ([]*Message, error) {
@@ -142,130 +472,261 @@ func (s *StreamService) getInboxUnread(ctx context.Context, beforeID string) ([]
 	return append(comments, directMessages...), err
 }
 
-// TODO: Generalize these two functions to have the same body... Maybe when generics is released ;)
-// InboxUnread returns 3 channels, one for comments, DMs, and errors, in that order, plus a function to close the channel
+func (s *StreamService) getInbox(ctx context.Context, beforeID string) ([]*Message, error) {
+	comments, directMessages, _, err := s.client.Message.Inbox(ctx, &ListOptions{Limit: itemLimit, Before: beforeID})
+	return append(comments, directMessages...), err
+}
+
+// Reported streams the reported posts and comments of the specified subreddit.
+// It returns 3 channels, one for posts, comments, and errors, in that order, plus a function to close the channels.
+// An item is streamed again when it gets more reports, but not when some of them were dismissed.
+// The report counts are kept in the stream's high water mark, or its dedup store if it has one, so that a
+// stream resumed from a persisted mark doesn't stream the items it already did again.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
 func (s *StreamService) Reported(ctx context.Context, subreddit string, opts ...StreamOpt[Streamable]) (<-chan *Post, <-chan *Comment, <-chan error, func()) {
-	streamConfig := NewStreamConfig[Streamable]()
-	for _, opt := range opts {
-		opt(streamConfig)
+	// only stream an item again if it got more reports than before, not when some were dismissed
+	reports := newReportCounts(itemLimit * 10)
+	changed := func(seen DedupStore, item Streamable) bool {
+		switch v := item.(type) {
+		case *Post:
+			return reports.Record(seen, v.FullID, v.NumReports)
+		case *Comment:
+			return reports.Record(seen, v.FullID, v.NumReports)
+		}
+		return false
 	}
+	return doModStream(ctx, subreddit, s.getReported, changed, opts)
+}
 
-	ticker := time.NewTicker(streamConfig.Interval)
-	postsCh := make(chan *Post)
-	commentsCh := make(chan *Comment)
-	errsCh := make(chan error)
+func (s *StreamService) getReported(ctx context.Context, subreddit string, beforeID string) ([]*Post, []*Comment, error) {
+	post, comment, _, err := s.client.Moderation.Reported(ctx, subreddit, &ListOptions{Limit: itemLimit, Before: beforeID})
+	return post, comment, err
+}
 
-	var once sync.Once
-	stop := func() {
-		once.Do(func() {
-			ticker.Stop()
-			close(postsCh)
-			close(commentsCh)
-			close(errsCh)
-		})
+// ModQueue streams the posts and comments in the modqueue of the specified subreddit.
+// It returns 3 channels, one for posts, comments, and errors, in that order, plus a function to close the channels.
+// Items are streamed when they show up in the queue, and again whenever their state changes, e.g. when they get
+// reported again or edited. An item that left the queue and came back unchanged isn't streamed again.
+// Like with Reported, the states are kept in the stream's high water mark, or its dedup store if it has one.
+// A stream resumed from a persisted mark tells whether an item changed from the states in it, which means
+// that an item coming back in a state it was in before isn't streamed again until its state changes once more.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) ModQueue(ctx context.Context, subreddit string, opts ...StreamOpt[Streamable]) (<-chan *Post, <-chan *Comment, <-chan error, func()) {
+	states := newStateTracker[string](itemLimit * 10)
+	changed := func(seen DedupStore, item Streamable) bool {
+		id, state := item.GetFullID(), modQueueState(item)
+		last, ok := states.Record(id, state)
+		if !ok {
+			return recordState(seen, id, state)
+		}
+		if state == last {
+			return false
+		}
+		recordState(seen, id, state)
+		return true
 	}
+	return doModStream(ctx, subreddit, s.getModQueue, changed, opts)
+}
 
-	// originally used the "before" parameter, but if that post gets deleted, subsequent requests
-	// would just return empty listings; easier to just keep track of all post ids encountered
-	oldIDs := set{}
-	newIDs := set{}
-
-	go func() {
-		defer stop()
+func (s *StreamService) getModQueue(ctx context.Context, subreddit string, beforeID string) ([]*Post, []*Comment, error) {
+	posts, comments, _, err := s.client.Moderation.Queue(ctx, subreddit, &ListOptions{Limit: itemLimit, Before: beforeID})
+	return posts, comments, err
+}
 
-		var n int
-		infinite := streamConfig.MaxRequests == 0
+// Spam streams the posts and comments caught in the spam filter of the specified subreddit.
+// It returns 3 channels, one for posts, comments, and errors, in that order, plus a function to close the channels.
+// Every item is only streamed once, even if it gets approved and caught again later.
+// Like with Reported, the items are kept in the stream's high water mark, or its dedup store if it has one.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Spam(ctx context.Context, subreddit string, opts ...StreamOpt[Streamable]) (<-chan *Post, <-chan *Comment, <-chan error, func()) {
+	changed := func(seen DedupStore, item Streamable) bool {
+		return recordState(seen, item.GetFullID(), "")
+	}
+	return doModStream(ctx, subreddit, s.getSpam, changed, opts)
+}
 
-		latest := Timestamp{time.Unix(0, 0)}
+func (s *StreamService) getSpam(ctx context.Context, subreddit string, beforeID string) ([]*Post, []*Comment, error) {
+	posts, comments, _, err := s.client.Moderation.Spam(ctx, subreddit, &ListOptions{Limit: itemLimit, Before: beforeID})
+	return posts, comments, err
+}
 
-		for {
-			select {
-			case <-ctx.Done():
-				errsCh <- ctx.Err()
-				return
-			case <-ticker.C:
-			}
-			n++
+// Edited streams the posts and comments of the specified subreddit as they get edited.
+// It returns 3 channels, one for posts, comments, and errors, in that order, plus a function to close the channels.
+// An item is streamed again every time it gets edited.
+// Like with Reported, the edits are kept in the stream's high water mark, or its dedup store if it has one.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+func (s *StreamService) Edited(ctx context.Context, subreddit string, opts ...StreamOpt[Streamable]) (<-chan *Post, <-chan *Comment, <-chan error, func()) {
+	changed := func(seen DedupStore, item Streamable) bool {
+		return recordState(seen, item.GetFullID(), editedAt(item))
+	}
+	return doModStream(ctx, subreddit, s.getEdited, changed, opts)
+}
 
-			posts, comments, err := s.getReported(ctx, subreddit, streamConfig.HighWaterMark.Pop())
-			if err != nil {
-				errsCh <- err
-				if !infinite && n >= streamConfig.MaxRequests {
-					break
-				}
-				continue
-			}
+func (s *StreamService) getEdited(ctx context.Context, subreddit string, beforeID string) ([]*Post, []*Comment, error) {
+	posts, comments, _, err := s.client.Moderation.Edited(ctx, subreddit, &ListOptions{Limit: itemLimit, Before: beforeID})
+	return posts, comments, err
+}
 
-			for _, post := range posts {
-				id := fmt.Sprintf("%s%d", post.ID, post.NumReports)
+// editedAt returns when the item was last edited, or an empty string if it wasn't.
+func editedAt(item Streamable) string {
+	var edited *Timestamp
+	switch v := item.(type) {
+	case *Post:
+		edited = v.Edited
+	case *Comment:
+		edited = v.Edited
+	}
+	if edited == nil {
+		return ""
+	}
+	return edited.UTC().Format(time.RFC3339Nano)
+}
 
-				// if this comment id is already part of the set, it means that it and the ones
-				// after it in the list have already been streamed, so break out of the loop
-				if newIDs.Exists(id) || oldIDs.Exists(id) {
-					break
-				}
-				newIDs.Add(id)
+// modQueueState sums up the parts of an item that matter to moderators going through the queue.
+func modQueueState(item Streamable) string {
+	switch v := item.(type) {
+	case *Post:
+		return fmt.Sprintf("%d/%v/%s", v.NumReports, v.Edited, v.RemovedByCategory)
+	case *Comment:
+		return fmt.Sprintf("%d/%v", v.NumReports, v.Edited)
+	}
+	return ""
+}
 
-				// If the new map is 10 times larger than item limit, make it the old map and clear it
-				if len(newIDs) >= itemLimit*10 {
-					oldIDs = newIDs
-					newIDs = make(map[string]struct{})
-				}
+// doModStream streams the posts and comments of a moderation listing, such as the reports or the modqueue.
+// changed is called for every fetched item, and decides whether it's worth streaming, keeping track of
+// the items in seen, the dedup store of the stream.
+func doModStream(
+	ctx context.Context,
+	subreddit string,
+	fetch func(ctx context.Context, subreddit string, beforeID string) ([]*Post, []*Comment, error),
+	changed func(seen DedupStore, item Streamable) bool,
+	opts []StreamOpt[Streamable],
+) (<-chan *Post, <-chan *Comment, <-chan error, func()) {
+	streamConfig := NewStreamConfig[Streamable]()
+	for _, opt := range opts {
+		opt(streamConfig)
+	}
+	seen := streamConfig.dedupStore()
+	streamConfig.changed = func(item Streamable) bool {
+		return changed(seen, item)
+	}
 
-				if streamConfig.DiscardInitial {
-					streamConfig.DiscardInitial = false
-					break
-				}
+	getItems := func(ctx context.Context, subreddit string, beforeID string) ([]Streamable, error) {
+		posts, comments, err := fetch(ctx, subreddit, beforeID)
+		items := make([]Streamable, 0, len(posts)+len(comments))
+		for _, post := range posts {
+			items = append(items, post)
+		}
+		for _, comment := range comments {
+			items = append(items, comment)
+		}
+		return items, err
+	}
+	split := func(item Streamable) (*Post, *Comment, bool) {
+		post, ok := item.(*Post)
+		if ok {
+			return post, nil, true
+		}
+		comment, _ := item.(*Comment)
+		return nil, comment, false
+	}
+	return doStream2(ctx, subreddit, getItems, streamConfig, split, nil)
+}
 
-				if post.Created != nil && post.Created.After(latest.Time) {
-					latest = *post.Created
-					streamConfig.HighWaterMark.Push(post.FullID)
-				}
+// doStream2 is doStreamWithConfig for the listings that mix two kinds of items, e.g. posts and comments,
+// which are streamed into a channel each. split tells which channel an item goes to: the first one if it
+// returns true, along with the item as either type. If delivered isn't nil, it's called with every item once
+// it has been sent, and the error it returns, if any, is sent into the errors channel without stopping the stream.
+func doStream2[T Streamable, A, B any](
+	ctx context.Context,
+	subreddit string,
+	getThing func(context.Context, string, string) ([]T, error),
+	streamConfig *streamConfig[T],
+	split func(item T) (A, B, bool),
+	delivered func(ctx context.Context, item T) error,
+) (<-chan A, <-chan B, <-chan error, func()) {
+	streamConfig.wrapped = true
+	items, errs, stopStream := doStreamWithConfig(ctx, subreddit, getThing, streamConfig)
+
+	firstCh := make(chan A, streamConfig.Buffer)
+	secondCh := make(chan B, streamConfig.Buffer)
+	errsCh := make(chan error, streamConfig.errorsBuffer())
+
+	stopped := make(chan struct{})
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(stopped)
+			stopStream()
+		})
+	}
+	// like in doStreamWithConfig, draining on stop keeps the items coming until the context is done
+	var halted <-chan struct{} = stopped
+	if streamConfig.DrainOnStop {
+		halted = ctx.Done()
+	}
 
-				postsCh <- post
+	// the channels are closed once the underlying stream closed its own, so that nothing gets sent into closed ones
+	go func() {
+		defer func() {
+			close(firstCh)
+			close(secondCh)
+			close(errsCh)
+			if streamConfig.Controller != nil {
+				streamConfig.Controller.finish()
 			}
+		}()
+		sendErr := streamConfig.errSender(ctx, errsCh, stopped)
 
-			for _, comment := range comments {
-				id := fmt.Sprintf("%s%d", comment.ID, comment.NumReports)
-
-				// if this comment id is already part of the set, it means that it and the ones
-				// after it in the list have already been streamed, so break out of the loop
-				if newIDs.Exists(id) || oldIDs.Exists(id) {
-					break
+		for items != nil || errs != nil {
+			select {
+			case item, ok := <-items:
+				if !ok {
+					items = nil
+					continue
 				}
-				newIDs.Add(id)
-
-				// If the new map is 10 times larger than item limit, make it the old map and clear it
-				if len(newIDs) >= itemLimit*10 {
-					oldIDs = newIDs
-					newIDs = make(map[string]struct{})
+				first, second, isFirst := split(item)
+				if isFirst {
+					select {
+					case firstCh <- first:
+					case <-halted:
+						continue
+					case <-ctx.Done():
+						continue
+					}
+				} else {
+					select {
+					case secondCh <- second:
+					case <-halted:
+						continue
+					case <-ctx.Done():
+						continue
+					}
 				}
-
-				if streamConfig.DiscardInitial {
-					streamConfig.DiscardInitial = false
-					break
+				if streamConfig.Controller != nil {
+					streamConfig.Controller.delivered()
 				}
-
-				if comment.Created != nil && comment.Created.After(latest.Time) {
-					latest = *comment.Created
-					streamConfig.HighWaterMark.Push(comment.FullID)
+				if delivered != nil {
+					if err := delivered(ctx, item); err != nil {
+						sendErr(&StreamError{Err: err})
+					}
 				}
-
-				commentsCh <- comment
-			}
-
-			if !infinite && n >= streamConfig.MaxRequests {
-				break
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				sendErr(err)
 			}
 		}
 	}()
 
-	return postsCh, commentsCh, errsCh, stop
-}
-
-func (s *StreamService) getReported(ctx context.Context, subreddit string, beforeID string) ([]*Post, []*Comment, error) {
-	post, comment, _, err := s.client.Moderation.Reported(ctx, subreddit, &ListOptions{Limit: itemLimit, Before: beforeID})
-	return post, comment, err
+	return firstCh, secondCh, errsCh, stop
 }
 
 func (s *StreamService) getComments(ctx context.Context, subreddit string, beforeID string) ([]*Comment, error) {
@@ -276,17 +737,40 @@ func (s *StreamService) getComments(ctx context.Context, subreddit string, befor
 	return comments, nil
 }
 
+// CommentsStream streams comments from the entirety of reddit, or whatever subreddit is provided.
+// Like with Posts, ctx.Err() is sent into the errors channel when ctx is cancelled, unless nothing receives it
+// within a second of real time.
+//
+// Deprecated: Use Comments instead.
+func (s *StreamService) CommentsStream(ctx context.Context, subreddit string, opts ...StreamOpt[*Comment]) (<-chan *Comment, <-chan error, func()) {
+	return s.Comments(ctx, subreddit, opts...)
+}
+
 // Comments streams comments from the entirety of reddit, or whatever subreddit is provided
 // It returns 2 channels and a function:
 //   - a channel into which new comments will be sent
 //   - a channel into which any errors will be sent
 //   - a function that the client can call once to stop the streaming and close the channels
 //
+// To resume streaming after a known comment, pass its full ID with WithStartFromFullID.
+//
+// When ctx is cancelled, ctx.Err() is sent into the errors channel before the channels are closed, like with Posts,
+// unless nothing receives it within a second of real time.
+//
 // Because of the 100 post limit imposed by Reddit when fetching comments, some high-traffic
 // streams might drop submissions between API requests, such as when streaming r/all.
+func (s *StreamService) Comments(ctx context.Context, subreddit string, opts ...StreamOpt[*Comment]) (<-chan *Comment, <-chan error, func()) {
+	streamConfig := NewStreamConfig[*Comment]()
+	for _, opt := range opts {
+		opt(streamConfig)
+	}
+	streamConfig.getAfter = s.getCommentsAfter
+	return doStreamWithConfig(ctx, subreddit, s.getComments, streamConfig)
+}
 
-func (s *StreamService) CommentsStream(ctx context.Context, subreddit string, opts ...StreamOpt[*Comment]) (<-chan *Comment, <-chan error, func()) {
-	return doStream(ctx, subreddit, s.getComments, opts...)
+func (s *StreamService) getCommentsAfter(ctx context.Context, subreddit string, afterID string, limit int) ([]*Comment, error) {
+	comments, _, err := s.client.Subreddit.NewComments(ctx, subreddit, &ListOptions{Limit: limit, After: afterID})
+	return comments, err
 }
 
 type Streamable interface {
@@ -294,90 +778,677 @@ type Streamable interface {
 	GetCreated() *Timestamp
 }
 
+// hasAuthor reports whether the item has an author. Items that don't carry an author at all,
+// such as mod actions, are considered to have one.
+func hasAuthor(item Streamable) bool {
+	if a, ok := item.(interface{ HasAuthor() bool }); ok {
+		return a.HasAuthor()
+	}
+	return true
+}
+
+// retryable reports whether a failed fetch may succeed if it's tried again.
+// Errors that stop the stream, and the stream's context being done, aren't worth retrying.
+func retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return !fatal(err)
+}
+
+// belowScore reports whether the item's score is known to be below min.
+// Items without a score, or whose score is hidden for now, aren't.
+func belowScore(item Streamable, min int) bool {
+	if h, ok := item.(interface{ IsScoreHidden() bool }); ok && h.IsScoreHidden() {
+		return false
+	}
+	if s, ok := item.(interface{ GetScore() int }); ok {
+		return s.GetScore() < min
+	}
+	return false
+}
+
+// belowUpvoteRatio reports whether the item's upvote ratio is known to be below min.
+// Items without a ratio aren't.
+func belowUpvoteRatio(item Streamable, min float64) bool {
+	r, ok := item.(interface{ GetUpvoteRatio() float32 })
+	if !ok || r.GetUpvoteRatio() == 0 {
+		return false
+	}
+	// the ratio only has the precision of a float32, e.g. 0.9 would be below a float64 0.9
+	return r.GetUpvoteRatio() < float32(min)
+}
+
+// linksToAny reports whether the item links to one of the domains.
+// Items that don't link anywhere always do.
+func linksToAny(item Streamable, domains []string) bool {
+	l, ok := item.(interface{ LinksTo(domain string) bool })
+	if !ok {
+		return true
+	}
+	for _, domain := range domains {
+		if l.LinksTo(domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFlair reports whether the item is a post with the link flair text, ignoring case.
+// Items other than posts always do.
+func hasFlair(item Streamable, text string) bool {
+	post, ok := item.(*Post)
+	if !ok {
+		return true
+	}
+	return strings.EqualFold(post.LinkFlairText, text)
+}
+
+// estimateMissed estimates how many items were created between the newest one of the previous page
+// and the oldest one of a page that didn't reach back to it, assuming they were created at the same
+// rate as the ones in the page. It's 1 when that can't be told, e.g. for items without a creation time.
+func estimateMissed[T Streamable](items []T, previous time.Time) int {
+	var oldest, latest time.Time
+	for _, item := range items {
+		created := item.GetCreated()
+		if created == nil {
+			continue
+		}
+		if oldest.IsZero() || created.Before(oldest) {
+			oldest = created.Time
+		}
+		if created.After(latest) {
+			latest = created.Time
+		}
+	}
+
+	gap := oldest.Sub(previous)
+	span := latest.Sub(oldest)
+	if previous.IsZero() || gap <= 0 || span <= 0 {
+		return 1
+	}
+	missed := int(float64(len(items)-1) * float64(gap) / float64(span))
+	if missed < 1 {
+		return 1
+	}
+	return missed
+}
+
+// newestCreated returns the creation time of the newest item, or newest if it's more recent.
+func newestCreated[T Streamable](items []T, newest time.Time) time.Time {
+	for _, item := range items {
+		if created := item.GetCreated(); created != nil && created.After(newest) {
+			newest = created.Time
+		}
+	}
+	return newest
+}
+
+// recordState records the state of the item in seen, returning true if the item wasn't seen in that state before.
+// The states are recorded as the full ID of the item followed by the state, so that they don't get mixed up
+// with the full IDs the store holds otherwise, and can be persisted along with them.
+func recordState(seen DedupStore, id string, state string) bool {
+	key := id + "@" + state
+	if seen.Contains(key) {
+		return false
+	}
+	seen.Push(key)
+	return true
+}
+
+// reportCounts remembers the highest number of reports seen for each item, by full ID.
+// Only a count that's higher than the item ever had is recorded in the dedup store, as a state of the item,
+// so that a stream resumed from a persisted mark picks the counts up again.
+type reportCounts struct {
+	highest *stateTracker[int]
+	loaded  bool
+}
+
+func newReportCounts(limit int) *reportCounts {
+	return &reportCounts{highest: newStateTracker[int](limit)}
+}
+
+// Record registers the number of reports of the item, returning true if the item wasn't seen before
+// or if it has more reports than it ever had.
+func (r *reportCounts) Record(seen DedupStore, id string, numReports int) bool {
+	if !r.loaded {
+		r.loaded = true
+		r.load(seen)
+	}
+
+	highest, ok := r.highest.Record(id, numReports)
+	if ok && highest >= numReports {
+		r.highest.Record(id, highest)
+		return false
+	}
+	// the item may have been forgotten about since, or come from a store whose counts couldn't be loaded
+	if !ok && seen.Contains(id+"@"+strconv.Itoa(numReports)) {
+		return false
+	}
+	return recordState(seen, id, strconv.Itoa(numReports))
+}
+
+// load picks up the counts recorded in seen, if it can tell what it holds, like a high water mark can.
+func (r *reportCounts) load(seen DedupStore) {
+	store, ok := seen.(interface{ Items() []string })
+	if !ok {
+		return
+	}
+	for _, key := range store.Items() {
+		i := strings.LastIndex(key, "@")
+		if i < 0 {
+			continue
+		}
+		n, err := strconv.Atoi(key[i+1:])
+		if err != nil {
+			continue
+		}
+		id := key[:i]
+		if highest, ok := r.highest.Record(id, n); ok && highest > n {
+			r.highest.Record(id, highest)
+		}
+	}
+}
+
+// stateTracker remembers the last state seen for each item, by full ID.
+// Once it holds limit items, it forgets about the least recently seen one.
+type stateTracker[S any] struct {
+	limit int
+	// most recently seen first
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+type trackedState[S any] struct {
+	id    string
+	state S
+}
+
+func newStateTracker[S any](limit int) *stateTracker[S] {
+	return &stateTracker[S]{limit: limit, order: list.New(), elements: make(map[string]*list.Element)}
+}
+
+// Record registers the state of the item, returning the state it was last seen in, if it was seen before.
+func (r *stateTracker[S]) Record(id string, state S) (S, bool) {
+	if e, ok := r.elements[id]; ok {
+		tracked := e.Value.(*trackedState[S])
+		last := tracked.state
+		tracked.state = state
+		r.order.MoveToFront(e)
+		return last, true
+	}
+
+	r.elements[id] = r.order.PushFront(&trackedState[S]{id: id, state: state})
+	if r.order.Len() > r.limit {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.elements, oldest.Value.(*trackedState[S]).id)
+	}
+	var none S
+	return none, false
+}
+
+// backfillItems pages through the listing that came before the first page of a stream, until it has
+// as many items as its first page limit, or it reaches the end of the listing or an item that was already seen.
+// Errors are sent with sendErr, keeping the items fetched so far.
+func backfillItems[T Streamable](ctx context.Context, subreddit string, streamConfig *streamConfig[T], items []T, sendErr func(error)) []T {
+	page := items
+	for len(page) == itemLimit && len(items) < streamConfig.FirstPageLimit {
+		if seenAny(page, streamConfig.dedupStore()) {
+			break
+		}
+
+		limit := streamConfig.FirstPageLimit - len(items)
+		if limit > itemLimit {
+			limit = itemLimit
+		}
+
+		var err error
+		page, err = streamConfig.getAfter(ctx, subreddit, page[len(page)-1].GetFullID(), limit)
+		if err != nil {
+			sendErr(err)
+			break
+		}
+		items = append(items, page...)
+	}
+	return items
+}
+
+func seenAny[T Streamable](items []T, store DedupStore) bool {
+	for _, item := range items {
+		if store.Contains(item.GetFullID()) {
+			return true
+		}
+	}
+	return false
+}
+
 func doStream[T Streamable](ctx context.Context, subreddit string, getThing func(context.Context, string, string) ([]T, error), opts ...StreamOpt[T]) (<-chan T, <-chan error, func()) {
 	streamConfig := NewStreamConfig[T]()
 	for _, opt := range opts {
 		opt(streamConfig)
 	}
+	return doStreamWithConfig(ctx, subreddit, getThing, streamConfig)
+}
 
-	ticker := time.NewTicker(streamConfig.Interval)
-	itemCh := make(chan T)
-	errsCh := make(chan error)
+// doStreamWithConfig is doStream for callers that need to inspect the applied options themselves,
+// e.g. to forward them to the getter.
+func doStreamWithConfig[T Streamable](ctx context.Context, subreddit string, getThing func(context.Context, string, string) ([]T, error), streamConfig *streamConfig[T]) (<-chan T, <-chan error, func()) {
+	if streamConfig.AdaptiveMax > 0 {
+		streamConfig.Interval = streamConfig.clampInterval(streamConfig.Interval)
+	}
+	ticker := streamConfig.Clock.NewTicker(streamConfig.nextInterval())
+	if streamConfig.Controller != nil {
+		streamConfig.Controller.attach(ticker)
+	}
+	itemCh := make(chan T, streamConfig.Buffer)
+	errsCh := make(chan error, streamConfig.errorsBuffer())
 
+	// the channels are closed by the stream's goroutine once it's done, so that it never sends into closed ones
+	stopped := make(chan struct{})
 	var once sync.Once
 	stop := func() {
 		once.Do(func() {
-			ticker.Stop()
-			close(itemCh)
-			close(errsCh)
+			close(stopped)
 		})
 	}
+	// the reason is recorded even when the stream is wrapped, which marks it as done once its own channels are closed
+	closeChannels := func(cause error) {
+		ticker.Stop()
+		if streamConfig.Controller != nil {
+			streamConfig.Controller.end(cause)
+		}
+		close(itemCh)
+		close(errsCh)
+		if streamConfig.Controller != nil && !streamConfig.wrapped {
+			streamConfig.Controller.finish()
+		}
+	}
+	sendErr := streamConfig.errSender(ctx, errsCh, stopped)
+	// items stop being delivered once the stream is stopped, unless it drains on stop,
+	// in which case only the context being done cuts the delivery of the fetched items short
+	var halted <-chan struct{} = stopped
+	if streamConfig.DrainOnStop {
+		halted = ctx.Done()
+	}
 
 	// originally used the "before" parameter, but if that post gets deleted, subsequent requests
-	// would just return empty listings; easier to just keep track of all comment ids encountered
-	oldIDs := set{}
-	newIDs := set{}
+	// would just return empty listings; easier to keep track of the items encountered in the high water mark.
+	// If it already has marks, e.g. from WithStartFromFullID, the stream resumes from them
+	resuming := streamConfig.HighWaterMark.Len() > 0
+	// the moderation streams can stream an item again once it changed, so the marks they resumed from
+	// aren't held against the items, unlike the ones marked as seen
+	var marks set
+	if streamConfig.changed != nil && resuming {
+		marks = newSetFromSlice(streamConfig.HighWaterMark.Items())
+	}
+	seen := streamConfig.dedupStore()
+	backfill := streamConfig.FirstPageLimit > 0 && streamConfig.getAfter != nil && streamConfig.GetFunc == nil && !streamConfig.UseDumbLogic
+
+	// with the dumb logic, the "before" parameter is used after all, starting from the mark if there's one
+	var before string
+	if streamConfig.UseDumbLogic && resuming {
+		before = streamConfig.HighWaterMark.Top()
+	}
+
+	if err := streamConfig.validate(); err != nil {
+		go func() {
+			streamErr := &StreamError{Err: err, Fatal: true}
+			defer closeChannels(streamErr)
+			sendErr(streamErr)
+		}()
+		return itemCh, errsCh, stop
+	}
 
 	go func() {
-		defer stop()
+		cause := ErrStreamStopped
+		defer func() { closeChannels(cause) }()
 
 		infinite := streamConfig.MaxRequests == 0
-		latest := Timestamp{time.Unix(0, 0)}
 		var n int
+		var empty int
+		// whether a page went through deduplication yet, and the creation time of the newest item in it
+		var polled bool
+		var newest time.Time
+
+		// why the stream ended, to be logged once it did
+		reason := "stopped"
+		defer func() {
+			streamConfig.log("info", "stream stopped", "subreddit", subreddit, "reason", reason, "requests", n)
+		}()
+
+		// items can be emitted from another goroutine, e.g. with prefetching, hence the atomic counter
+		var emitted int64
+		var stats StreamStats
+		report := func() {}
+		if streamConfig.Metrics != nil {
+			reporter := newStatsReporter(streamConfig.Metrics)
+			defer reporter.Close()
+			report = func() {
+				stats.Requests = n
+				stats.ItemsEmitted = int(atomic.LoadInt64(&emitted))
+				reporter.Report(stats)
+			}
+		}
+
+		// the cursor only moves once the consumer received the item, which the wrapping stream tells the
+		// controller about when there's one, since it's what the consumer receives items from
+		emit := func(item T) bool {
+			// a pending send gives up once stopped or once the context is done, so that neither can leave it stuck
+			select {
+			case itemCh <- item:
+				atomic.AddInt64(&emitted, 1)
+				if streamConfig.Controller != nil && !streamConfig.wrapped {
+					streamConfig.Controller.delivered()
+				}
+				return true
+			case <-halted:
+				return false
+			case <-ctx.Done():
+				return false
+			}
+		}
+		emitPage := func(items []T) bool {
+			for _, item := range items {
+				if !emit(item) {
+					return false
+				}
+			}
+			return true
+		}
+		// the page is expected before it's handed off, so that pages are expected in the order they're emitted
+		expect := func(items []T) {
+			if streamConfig.Controller != nil {
+				page := make([]Streamable, len(items))
+				for i, item := range items {
+					page[i] = item
+				}
+				streamConfig.Controller.expect(page)
+			}
+		}
+		deliver := func(items []T) {
+			expect(items)
+			emitPage(items)
+		}
+
+		// with prefetching, pages are handed off to a separate goroutine to be emitted,
+		// so that the next fetch doesn't have to wait for the consumer
+		if streamConfig.Prefetch {
+			pages := make(chan []T, 1)
+			emitterDone := make(chan struct{})
+			go func() {
+				defer close(emitterDone)
+				for items := range pages {
+					if !emitPage(items) {
+						return
+					}
+				}
+			}()
+			defer func() {
+				close(pages)
+				<-emitterDone
+			}()
+			deliver = func(items []T) {
+				if len(items) == 0 {
+					return
+				}
+				expect(items)
+				select {
+				case pages <- items:
+				case <-emitterDone:
+				}
+			}
+		}
+
+		var compacted *compactor[T]
+		var flush <-chan time.Time
+		if streamConfig.Compaction > 0 {
+			compacted = newCompactor[T]()
+			flushTicker := streamConfig.Clock.NewTicker(streamConfig.Compaction)
+			defer flushTicker.Stop()
+			flush = flushTicker.C()
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
+				reason = ctx.Err().Error()
+				cause = fmt.Errorf("%w: %v", ErrStreamCancelled, ctx.Err())
+				sendErr(ctx.Err())
 				return
-			case <-ticker.C:
+			case <-stopped:
+				if streamConfig.DrainOnStop && compacted != nil {
+					deliver(compacted.Flush())
+				}
+				return
+			case <-flush:
+				deliver(compacted.Flush())
+				continue
+			case <-ticker.C():
+			}
+			streamConfig.rejitter(ticker)
+
+			breaker := streamConfig.CircuitBreaker
+			if breaker != nil {
+				allowed, event := breaker.allow(streamConfig.Clock.Now())
+				if event != nil {
+					sendErr(event)
+				}
+				if !allowed {
+					streamConfig.log("debug", "circuit breaker is open, skipping fetch", "subreddit", subreddit)
+					continue
+				}
 			}
+
 			n++
-			var items []T
-			var err error
-			if streamConfig.GetFunc != nil {
-				items, err = streamConfig.GetFunc(ctx, subreddit, streamConfig.HighWaterMark.Pop())
-			} else {
-				items, err = getThing(ctx, subreddit, streamConfig.HighWaterMark.Pop())
+			fetch := func() ([]T, error) {
+				if streamConfig.GetFunc != nil {
+					return streamConfig.GetFunc(ctx, subreddit, before)
+				}
+				return getThing(ctx, subreddit, before)
+			}
+			items, err := fetch()
+			retry := 0
+			for ; retry < streamConfig.MaxRetries && retryable(err); retry++ {
+				streamConfig.log("warn", "fetch failed, retrying", "subreddit", subreddit, "retry", retry+1, "backoff", streamConfig.retryBackoff(retry), "err", err)
+				backoff := streamConfig.Clock.NewTicker(streamConfig.retryBackoff(retry))
+				select {
+				case <-ctx.Done():
+					backoff.Stop()
+					reason = ctx.Err().Error()
+					cause = fmt.Errorf("%w: %v", ErrStreamCancelled, ctx.Err())
+					sendErr(ctx.Err())
+					return
+				case <-stopped:
+					backoff.Stop()
+					return
+				case <-backoff.C():
+				}
+				backoff.Stop()
+				items, err = fetch()
+			}
+			// the next fetch happens a whole interval after the last retry, not whenever the ticker was due
+			if retry > 0 {
+				ticker.Reset(streamConfig.nextInterval())
 			}
 			if err != nil {
-				errsCh <- err
+				// fatal errors would only come back on every fetch, so the stream stops instead
+				streamErr := newStreamError(err, subreddit)
+				streamConfig.log("error", "fetch failed", "subreddit", subreddit, "request", n, "fatal", streamErr.Fatal, "err", err)
+				sendErr(streamErr)
+				stats.ErrorsSeen++
+				report()
+				if streamErr.Fatal {
+					reason = "fatal error"
+					cause = streamErr
+					break
+				}
+				if breaker != nil {
+					if event := breaker.record(err, streamConfig.Clock.Now()); event != nil {
+						sendErr(event)
+					}
+				}
 				if !infinite && n >= streamConfig.MaxRequests {
+					reason = "max requests reached"
+					cause = ErrStreamCompleted
 					break
 				}
 				continue
 			}
+			if breaker != nil {
+				if event := breaker.record(nil, streamConfig.Clock.Now()); event != nil {
+					sendErr(event)
+				}
+			}
+			if streamConfig.AdaptiveMax > 0 {
+				streamConfig.Interval = streamConfig.adaptInterval(len(items))
+				ticker.Reset(streamConfig.nextInterval())
+			}
 
-			for _, item := range items {
+			if backfill {
+				backfill = false
+				items = backfillItems(ctx, subreddit, streamConfig, items, sendErr)
+			}
+
+			if streamConfig.Controller != nil {
+				for _, id := range streamConfig.Controller.takeSeen() {
+					seen.Push(id)
+				}
+			}
+
+			now := streamConfig.Clock.Now()
+			// too young, check it again on the next fetch
+			tooYoung := func(item T) bool {
+				return streamConfig.MinAge > 0 && item.GetCreated() != nil && now.Sub(item.GetCreated().Time) < streamConfig.MinAge
+			}
+			discard := streamConfig.DiscardInitial
+			streamConfig.DiscardInitial = false
+			resumed := resuming
+			resuming = false
+
+			// the listing is newest first, so the next fetch only gets what came after the first item
+			if streamConfig.UseDumbLogic && len(items) > 0 {
+				before = items[0].GetFullID()
+			}
+
+			var page []T
+			var fresh int
+			for i, item := range items {
 				id := item.GetFullID()
 
-				// if this item id is already part of the set, it means that it and the ones
-				// after it in the list have already been streamed, so break out of the loop
-				if newIDs.Exists(id) || oldIDs.Exists(id) {
-					break
+				if streamConfig.changed != nil {
+					// everything from the mark on was streamed before the stream was restarted,
+					// so it's only recorded as it is now
+					if resumed && streamConfig.HighWaterMark.Contains(id) {
+						for _, older := range items[i:] {
+							streamConfig.changed(older)
+						}
+						break
+					}
+					if seen.Contains(id) && !marks.Exists(id) {
+						continue
+					}
+					if tooYoung(item) || !streamConfig.changed(item) {
+						continue
+					}
+				} else if !streamConfig.UseDumbLogic {
+					// with the dumb logic, the "before" parameter takes care of only getting new items
+					// skip items that were already streamed. Not every listing is sorted by creation time
+					// (e.g. top posts), so the ones after it could still be new.
+					// When resuming though, everything after the mark was streamed before the stream was restarted
+					if seen.Contains(id) || (resumed && streamConfig.HighWaterMark.Contains(id)) {
+						if resumed {
+							// the mark itself was streamed too, and a separate dedup store doesn't know about it yet
+							for _, older := range items[i:] {
+								// some of them could be marks already, which would only take up room again
+								if olderID := older.GetFullID(); !seen.Contains(olderID) {
+									seen.Push(olderID)
+								}
+							}
+							break
+						}
+						continue
+					}
+
+					if tooYoung(item) {
+						continue
+					}
+					// once it's full, the store forgets about some of the items, e.g. the oldest ones for the mark
+					seen.Push(id)
 				}
-				newIDs.Add(id)
+				fresh++
 
-				// If the new map is 10 times larger than item limit, make it the old map and clear it
-				if len(newIDs) >= itemLimit*10 {
-					oldIDs = newIDs
-					newIDs = make(map[string]struct{})
+				// the whole first page is recorded as seen, so none of it gets streamed later on
+				if discard {
+					continue
 				}
 
-				if streamConfig.DiscardInitial {
-					streamConfig.DiscardInitial = false
-					break
+				if streamConfig.RequireAuthor && !hasAuthor(item) {
+					continue
+				}
+				if streamConfig.MinScore != nil && belowScore(item, *streamConfig.MinScore) {
+					continue
+				}
+				if streamConfig.MinUpvoteRatio > 0 && belowUpvoteRatio(item, streamConfig.MinUpvoteRatio) {
+					continue
+				}
+				if len(streamConfig.Domains) > 0 && !linksToAny(item, streamConfig.Domains) {
+					continue
+				}
+				if !streamConfig.kept(item) {
+					continue
+				}
+				if streamConfig.ContentHash != nil {
+					if hash := streamConfig.ContentHash(item); hash != "" {
+						if streamConfig.contentHashes.Contains(hash) {
+							continue
+						}
+						streamConfig.contentHashes.Push(hash)
+					}
+				}
+				if !streamConfig.sampled() {
+					continue
 				}
 
-				if !streamConfig.UseDumbLogic && item.GetCreated() != nil && item.GetCreated().After(latest.Time) {
-					latest = *item.GetCreated()
-					streamConfig.HighWaterMark.Push(item.GetFullID())
+				if compacted != nil {
+					compacted.Add(item)
+					continue
 				}
+				page = append(page, item)
+			}
+
+			// when the whole page is new, the listing might have gone past the items that were already seen
+			if streamConfig.DropDetection != nil && (polled || resumed) && len(items) >= itemLimit && fresh == len(items) {
+				streamConfig.DropDetection(estimateMissed(items, newest))
+			}
+			polled = true
+			newest = newestCreated(items, newest)
+			streamConfig.log("debug", "fetched page", "subreddit", subreddit, "request", n, "items", len(items), "new", fresh)
 
-				itemCh <- item
+			deliver(page)
+			stats.LastPageSize = len(items)
+			report()
+
+			if fresh == 0 {
+				empty++
+			} else {
+				empty = 0
+			}
+			if streamConfig.MaxEmpty > 0 && empty >= streamConfig.MaxEmpty {
+				reason = "max consecutive empty fetches reached"
+				cause = ErrStreamCompleted
+				break
 			}
 			if !infinite && n >= streamConfig.MaxRequests {
+				reason = "max requests reached"
+				cause = ErrStreamCompleted
 				break
 			}
 		}
+
+		if compacted != nil {
+			deliver(compacted.Flush())
+		}
 	}()
 	return itemCh, errsCh, stop
 }
@@ -0,0 +1,216 @@
+package reddit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// StreamController gives access to a running stream.
+// Create one with NewStreamController and hand it to a stream with WithStreamController.
+// A controller should only be used with a single stream. It is safe for concurrent use.
+type StreamController struct {
+	mu       sync.Mutex
+	ticker   Ticker
+	interval time.Duration
+	// whether the stream picked up the interval yet
+	intervalTaken bool
+	seen          []string
+
+	cursor        string
+	cursorCreated time.Time
+	// the pages that were handed to the consumer, oldest first, and how many items of the first one it received
+	pending  [][]Streamable
+	received int
+
+	done     chan struct{}
+	doneOnce sync.Once
+	err      error
+}
+
+// NewStreamController returns a controller that isn't attached to any stream yet.
+func NewStreamController() *StreamController {
+	return &StreamController{}
+}
+
+// Done returns a channel that's closed once the stream is done: its goroutine returned and its channels are closed,
+// whether it was stopped, its context is done, or it ran out of requests. After calling the stream's stop function,
+// receive from it to know that the stream won't do anything anymore, e.g. before exiting.
+func (c *StreamController) Done() <-chan struct{} {
+	return c.doneCh()
+}
+
+// Wait blocks until the stream is done, like receiving from Done, or until the context is done,
+// in which case it returns the context's error.
+func (c *StreamController) Wait(ctx context.Context) error {
+	select {
+	case <-c.doneCh():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Err returns nil until the stream is done, then why it ended: ErrStreamCompleted once it reached its max requests
+// or its max consecutive empty fetches, ErrStreamStopped once its stop function was called, an error wrapping
+// ErrStreamCancelled once its context is done, or the fatal *StreamError it sent last, e.g. wrapping ErrSubredditNotFound.
+func (c *StreamController) Err() error {
+	select {
+	case <-c.doneCh():
+	default:
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// end records why the stream ended, ahead of it being marked as done.
+func (c *StreamController) end(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// finish marks the stream as done.
+func (c *StreamController) finish() {
+	done := c.doneCh()
+	c.doneOnce.Do(func() {
+		close(done)
+	})
+}
+
+func (c *StreamController) doneCh() chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done == nil {
+		c.done = make(chan struct{})
+	}
+	return c.done
+}
+
+// TriggerFetch makes the stream fetch right away instead of waiting for its next tick.
+// It only has an effect when the stream runs on a FakeClock, so that tests can step
+// through a stream one fetch at a time. With the default clock, it's a no-op.
+func (c *StreamController) TriggerFetch() {
+	c.mu.Lock()
+	ticker := c.ticker
+	c.mu.Unlock()
+
+	if t, ok := ticker.(interface{ trigger() }); ok {
+		t.trigger()
+	}
+}
+
+// SetInterval changes the interval between the fetches of the stream, starting over from now: the next fetch
+// happens once d has elapsed. If the controller isn't attached to a stream yet, the stream starts with it.
+// The stream's jitter, if any, still applies on top of it.
+func (c *StreamController) SetInterval(d time.Duration) error {
+	if d <= 0 {
+		return errors.New("d: must be greater than 0")
+	}
+
+	c.mu.Lock()
+	c.interval = d
+	c.intervalTaken = false
+	ticker := c.ticker
+	c.mu.Unlock()
+
+	if ticker != nil {
+		ticker.Reset(d)
+	}
+	return nil
+}
+
+// takeInterval returns the interval set with SetInterval, if it was called since the stream last took it.
+func (c *StreamController) takeInterval() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.interval <= 0 || c.intervalTaken {
+		return 0, false
+	}
+	c.intervalTaken = true
+	return c.interval, true
+}
+
+// MarkSeen records the full IDs as already seen by the stream, so that it won't emit those items.
+// This is useful when another component has fetched and processed them separately.
+// The IDs are picked up by the stream before it goes through the items of its next fetch.
+func (c *StreamController) MarkSeen(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen = append(c.seen, ids...)
+}
+
+// takeSeen returns the IDs marked as seen since the last call.
+func (c *StreamController) takeSeen() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := c.seen
+	c.seen = nil
+	return ids
+}
+
+// Cursor returns the full ID of the newest item of the pages the consumer received in full so far, or an empty string
+// if it hasn't received any. Persist it once the stream is done to resume the stream where it left off, with
+// WithStartFromFullID. Pages are emitted newest first, so the items of a page the consumer only received some of
+// aren't accounted for: a resumed stream emits them again rather than losing the ones that weren't received.
+func (c *StreamController) Cursor() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cursor
+}
+
+// expect records the page of items the stream is about to send to the consumer.
+func (c *StreamController) expect(items []Streamable) {
+	if len(items) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, items)
+}
+
+// delivered records that the consumer received the next item it was expected to. Once it received all the items
+// of a page, the cursor moves past them.
+func (c *StreamController) delivered() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 {
+		return
+	}
+	c.received++
+	if page := c.pending[0]; c.received == len(page) {
+		for _, item := range page {
+			c.advance(item)
+		}
+		c.pending = c.pending[1:]
+		c.received = 0
+	}
+}
+
+// advance moves the cursor to the item, if it's newer than the current one.
+// Items without a creation time always move it, since they can't be compared.
+func (c *StreamController) advance(item Streamable) {
+	created := item.GetCreated()
+	if created == nil {
+		c.cursor = item.GetFullID()
+		return
+	}
+	if c.cursor == "" || created.After(c.cursorCreated) {
+		c.cursor = item.GetFullID()
+		c.cursorCreated = created.Time
+	}
+}
+
+func (c *StreamController) attach(ticker Ticker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ticker = ticker
+	if c.interval > 0 {
+		ticker.Reset(c.interval)
+	}
+}
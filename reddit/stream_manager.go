@@ -0,0 +1,223 @@
+package reddit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// StreamState is the state of a stream registered with a StreamManager.
+type StreamState int
+
+const (
+	// StreamRunning means the stream is still going.
+	StreamRunning StreamState = iota
+	// StreamStopping means the stream was asked to stop, but hasn't closed its channels yet.
+	StreamStopping
+	// StreamStopped means the stream closed its channels, either because it was stopped or because it was done.
+	StreamStopped
+)
+
+func (s StreamState) String() string {
+	switch s {
+	case StreamRunning:
+		return "running"
+	case StreamStopping:
+		return "stopping"
+	case StreamStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("StreamState(%d)", int(s))
+	}
+}
+
+// StreamStatus is the state of a stream registered with a StreamManager, along with the name it was registered with.
+type StreamStatus struct {
+	Name  string
+	State StreamState
+}
+
+// StreamManager keeps track of running streams, so that they can all be stopped at once, e.g. on SIGTERM.
+// Register streams with RegisterStream, or with RegisterStream2 for the ones that emit into 2 channels,
+// such as Reported. It is safe for concurrent use.
+type StreamManager struct {
+	mu      sync.Mutex
+	streams []*managedStream
+}
+
+type managedStream struct {
+	name     string
+	state    StreamState
+	stop     func()
+	stopping chan struct{}
+	once     sync.Once
+	// closed once the channels of the stream are
+	done chan struct{}
+}
+
+// NewStreamManager returns a manager without any streams.
+func NewStreamManager() *StreamManager {
+	return &StreamManager{}
+}
+
+// RegisterStream hands the stream to the manager, returning the channels to consume it from instead of its own.
+// They get closed once the stream's are. Once the stream is stopped, whatever it still sends is dropped.
+//
+//	posts, errs, stop := client.Stream.Posts(ctx, "subreddit")
+//	posts, errs = reddit.RegisterStream(manager, "posts", posts, errs, stop)
+func RegisterStream[T any](m *StreamManager, name string, items <-chan T, errs <-chan error, stop func()) (<-chan T, <-chan error) {
+	s := m.register(name, stop)
+
+	itemCh := make(chan T)
+	errsCh := make(chan error)
+	go func() {
+		defer func() {
+			close(itemCh)
+			close(errsCh)
+			m.stopped(s)
+		}()
+
+		// keep draining the stream until it's closed, even once stopped, so that it doesn't get stuck sending
+		for items != nil || errs != nil {
+			select {
+			case item, ok := <-items:
+				if !ok {
+					items = nil
+					continue
+				}
+				select {
+				case itemCh <- item:
+				case <-s.stopping:
+				}
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				select {
+				case errsCh <- err:
+				case <-s.stopping:
+				}
+			}
+		}
+	}()
+
+	return itemCh, errsCh
+}
+
+// RegisterStream2 is RegisterStream for the streams that emit into 2 channels, such as Reported or InboxUnread.
+//
+//	posts, comments, errs, stop := client.Stream.Reported(ctx, "subreddit")
+//	posts, comments, errs = reddit.RegisterStream2(manager, "reported", posts, comments, errs, stop)
+func RegisterStream2[A, B any](m *StreamManager, name string, first <-chan A, second <-chan B, errs <-chan error, stop func()) (<-chan A, <-chan B, <-chan error) {
+	s := m.register(name, stop)
+
+	firstCh := make(chan A)
+	secondCh := make(chan B)
+	errsCh := make(chan error)
+	go func() {
+		defer func() {
+			close(firstCh)
+			close(secondCh)
+			close(errsCh)
+			m.stopped(s)
+		}()
+
+		// like with RegisterStream, the stream is drained until it's closed
+		for first != nil || second != nil || errs != nil {
+			select {
+			case item, ok := <-first:
+				if !ok {
+					first = nil
+					continue
+				}
+				select {
+				case firstCh <- item:
+				case <-s.stopping:
+				}
+			case item, ok := <-second:
+				if !ok {
+					second = nil
+					continue
+				}
+				select {
+				case secondCh <- item:
+				case <-s.stopping:
+				}
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				select {
+				case errsCh <- err:
+				case <-s.stopping:
+				}
+			}
+		}
+	}()
+
+	return firstCh, secondCh, errsCh
+}
+
+func (m *StreamManager) register(name string, stop func()) *managedStream {
+	s := &managedStream{
+		name:     name,
+		stop:     stop,
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	m.mu.Lock()
+	m.streams = append(m.streams, s)
+	m.mu.Unlock()
+	return s
+}
+
+// stopped marks the stream as stopped, once its channels are closed.
+func (m *StreamManager) stopped(s *managedStream) {
+	m.mu.Lock()
+	s.state = StreamStopped
+	m.mu.Unlock()
+	close(s.done)
+}
+
+// Statuses returns the state of every registered stream, in the order they were registered.
+func (m *StreamManager) Statuses() []StreamStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]StreamStatus, len(m.streams))
+	for i, s := range m.streams {
+		statuses[i] = StreamStatus{Name: s.name, State: s.state}
+	}
+	return statuses
+}
+
+// StopAll stops every registered stream, and waits for all of them to close their channels.
+// If ctx is done before they do, it returns ctx.Err() without waiting any longer.
+func (m *StreamManager) StopAll(ctx context.Context) error {
+	m.mu.Lock()
+	streams := append([]*managedStream(nil), m.streams...)
+	for _, s := range streams {
+		if s.state == StreamRunning {
+			s.state = StreamStopping
+		}
+	}
+	m.mu.Unlock()
+
+	for _, s := range streams {
+		s.once.Do(func() {
+			close(s.stopping)
+			s.stop()
+		})
+	}
+
+	for _, s := range streams {
+		select {
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package reddit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamManager_StopAll(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_post1"}}]}}`)
+	})
+	mux.HandleFunc("/r/testsubreddit/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t1", "data": {"name": "t1_comment1"}}]}}`)
+	})
+
+	manager := NewStreamManager()
+
+	posts, postErrs, stopPosts := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+	)
+	posts, postErrs = RegisterStream(manager, "posts", posts, postErrs, stopPosts)
+
+	comments, commentErrs, stopComments := client.Stream.Comments(context.Background(), "testsubreddit",
+		WithStreamInterval[*Comment](time.Millisecond*10),
+	)
+	comments, commentErrs = RegisterStream(manager, "comments", comments, commentErrs, stopComments)
+
+	select {
+	case post := <-posts:
+		require.Equal(t, "t3_post1", post.FullID)
+	case err := <-postErrs:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a post")
+	}
+	select {
+	case comment := <-comments:
+		require.Equal(t, "t1_comment1", comment.FullID)
+	case err := <-commentErrs:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a comment")
+	}
+
+	require.Equal(t, []StreamStatus{
+		{Name: "posts", State: StreamRunning},
+		{Name: "comments", State: StreamRunning},
+	}, manager.Statuses())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, manager.StopAll(ctx))
+
+	_, ok := <-posts
+	require.False(t, ok)
+	_, ok = <-postErrs
+	require.False(t, ok)
+	_, ok = <-comments
+	require.False(t, ok)
+	_, ok = <-commentErrs
+	require.False(t, ok)
+
+	require.Equal(t, []StreamStatus{
+		{Name: "posts", State: StreamStopped},
+		{Name: "comments", State: StreamStopped},
+	}, manager.Statuses())
+
+	// stopping again is a no-op
+	require.NoError(t, manager.StopAll(ctx))
+}
+
+func TestStreamManager_RegisterStream2(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 1}},
+					{"kind": "t1", "data": {"name": "t1_comment1", "num_reports": 1}}
+				]
+			}
+		}`)
+	})
+
+	manager := NewStreamManager()
+
+	posts, comments, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit",
+		WithStreamInterval[Streamable](time.Millisecond*10),
+	)
+	posts, comments, errs = RegisterStream2(manager, "reported", posts, comments, errs, stop)
+
+	var ids []string
+	for len(ids) < 2 {
+		select {
+		case post := <-posts:
+			ids = append(ids, post.FullID)
+		case comment := <-comments:
+			ids = append(ids, comment.FullID)
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the reported items")
+		}
+	}
+	require.ElementsMatch(t, []string{"t3_post1", "t1_comment1"}, ids)
+	require.Equal(t, []StreamStatus{{Name: "reported", State: StreamRunning}}, manager.Statuses())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, manager.StopAll(ctx))
+
+	_, ok := <-posts
+	require.False(t, ok)
+	_, ok = <-comments
+	require.False(t, ok)
+	_, ok = <-errs
+	require.False(t, ok)
+	require.Equal(t, []StreamStatus{{Name: "reported", State: StreamStopped}}, manager.Statuses())
+}
+
+func TestStreamManager_StopAll_Timeout(t *testing.T) {
+	manager := NewStreamManager()
+
+	// a stream that never closes its channels
+	items := make(chan int)
+	errs := make(chan error)
+	RegisterStream(manager, "stuck", items, errs, func() {})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, manager.StopAll(ctx))
+	require.Equal(t, []StreamStatus{{Name: "stuck", State: StreamStopping}}, manager.Statuses())
+
+	close(items)
+	close(errs)
+}
+
+func TestStreamState_String(t *testing.T) {
+	require.Equal(t, "running", StreamRunning.String())
+	require.Equal(t, "stopping", StreamStopping.String())
+	require.Equal(t, "stopped", StreamStopped.String())
+	require.Equal(t, "StreamState(5)", StreamState(5).String())
+}
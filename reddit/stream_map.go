@@ -0,0 +1,19 @@
+package reddit
+
+// StreamMap applies fn to every item received from in, e.g. a stream's items channel, and sends the results into
+// the returned channel, in the same order. It's closed once in is. fn is called from a separate goroutine, one item
+// at a time, which waits for each result to be received before taking the next item, so keep reading the returned
+// channel until it's closed, or stop the stream behind in.
+//
+//	posts, errs, stop := client.Stream.Posts(ctx, "subreddit")
+//	titles := reddit.StreamMap(posts, func(post *reddit.Post) string { return post.Title })
+func StreamMap[T Streamable, R any](in <-chan T, fn func(T) R) <-chan R {
+	out := make(chan R)
+	go func() {
+		defer close(out)
+		for item := range in {
+			out <- fn(item)
+		}
+	}()
+	return out
+}
@@ -0,0 +1,71 @@
+package reddit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamMap(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post%d", "title": "Post %d"}}
+				]
+			}
+		}`, counter+1, counter+1)
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](3),
+		WithStreamErrorHandler[*Post](func(err error) {
+			t.Errorf("unexpected error: %v", err)
+		}),
+	)
+	defer stop()
+
+	titles := StreamMap(posts, func(post *Post) string {
+		return post.Title
+	})
+
+	// the titles channel is closed along with the stream's
+	var received []string
+	for title := range titles {
+		received = append(received, title)
+	}
+	require.Equal(t, []string{"Post 1", "Post 2", "Post 3"}, received)
+
+	_, ok := <-errs
+	require.False(t, ok)
+}
+
+func TestStreamMap_Close(t *testing.T) {
+	in := make(chan *Post)
+	out := StreamMap(in, func(post *Post) int {
+		return post.Score
+	})
+
+	in <- &Post{Score: 1}
+	require.Equal(t, 1, <-out)
+
+	close(in)
+	select {
+	case _, ok := <-out:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the channel to be closed")
+	}
+}
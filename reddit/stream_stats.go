@@ -0,0 +1,63 @@
+package reddit
+
+import "sync"
+
+// StreamStats are the running totals of a stream, as reported by WithStreamMetrics.
+type StreamStats struct {
+	// The number of fetches made so far, successful or not. Retries of a failed fetch don't count.
+	Requests int
+	// The number of items sent on the stream's channel so far.
+	ItemsEmitted int
+	// The number of fetches that failed so far, after any retries.
+	ErrorsSeen int
+	// The number of items returned by the last successful fetch, before any of them were filtered out.
+	LastPageSize int
+}
+
+// statsReporter hands stats over to a callback in a goroutine of its own, so that a slow
+// callback doesn't hold up the stream. If the callback hasn't returned yet by the time
+// the next stats come in, it only gets the most recent ones once it does.
+type statsReporter struct {
+	fn     func(StreamStats)
+	mu     sync.Mutex
+	latest *StreamStats
+	wake   chan struct{}
+	done   chan struct{}
+}
+
+func newStatsReporter(fn func(StreamStats)) *statsReporter {
+	r := &statsReporter{fn: fn, wake: make(chan struct{}, 1), done: make(chan struct{})}
+	go r.run()
+	return r
+}
+
+func (r *statsReporter) run() {
+	defer close(r.done)
+	for range r.wake {
+		r.mu.Lock()
+		stats := r.latest
+		r.latest = nil
+		r.mu.Unlock()
+
+		if stats != nil {
+			r.fn(*stats)
+		}
+	}
+}
+
+func (r *statsReporter) Report(stats StreamStats) {
+	r.mu.Lock()
+	r.latest = &stats
+	r.mu.Unlock()
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Close waits for the callback to get the last stats that were reported.
+func (r *statsReporter) Close() {
+	close(r.wake)
+	<-r.done
+}
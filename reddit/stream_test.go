@@ -1,9 +1,16 @@
 package reddit
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -309,3 +316,4958 @@ loop:
 
 	require.Len(t, expectedPostIDs, i)
 }
+
+func TestStreamService_Posts_RequireAuthor(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		switch counter {
+		case 0:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{
+							"kind": "t3",
+							"data": {
+								"name": "t3_post1",
+								"author": "user1"
+							}
+						},
+						{
+							"kind": "t3",
+							"data": {
+								"name": "t3_post2"
+							}
+						},
+						{
+							"kind": "t3",
+							"data": {
+								"name": "t3_post3",
+								"author": "user3"
+							}
+						}
+					]
+				}
+			}`)
+		case 1:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{
+							"kind": "t3",
+							"data": {
+								"name": "t3_post4",
+								"author": "user4"
+							}
+						},
+						{
+							"kind": "t3",
+							"data": {
+								"name": "t3_post2"
+							}
+						}
+					]
+				}
+			}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit", WithStreamInterval[*Post](time.Millisecond*10), WithStreamMaxRequests[*Post](2), WithStreamRequireAuthor[*Post]())
+	defer stop()
+
+	expectedPostIDs := []string{"t3_post1", "t3_post3", "t3_post4"}
+	var received []string
+
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			require.True(t, post.HasAuthor())
+			received = append(received, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, expectedPostIDs, received)
+}
+
+func TestStreamService_Posts_CircuitBreaker(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	var requestTimes []time.Time
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+		requestTimes = append(requestTimes, time.Now())
+
+		if counter < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{
+						"kind": "t3",
+						"data": {
+							"name": "t3_post1"
+						}
+					}
+				]
+			}
+		}`)
+	})
+
+	cooldown := time.Millisecond * 50
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](4),
+		WithStreamCircuitBreaker[*Post](2, cooldown),
+	)
+	defer stop()
+
+	var events []*CircuitBreakerEvent
+	var fetchErrs int
+	var received []string
+
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			received = append(received, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			if event, ok := err.(*CircuitBreakerEvent); ok {
+				events = append(events, event)
+				continue
+			}
+			fetchErrs++
+		}
+	}
+
+	require.Equal(t, 3, fetchErrs)
+	require.Equal(t, []string{"t3_post1"}, received)
+	require.Equal(t, []*CircuitBreakerEvent{
+		{From: CircuitClosed, To: CircuitOpen, Failures: 2},
+		{From: CircuitOpen, To: CircuitHalfOpen, Failures: 2},
+		{From: CircuitHalfOpen, To: CircuitOpen, Failures: 3},
+		{From: CircuitOpen, To: CircuitHalfOpen, Failures: 3},
+		{From: CircuitHalfOpen, To: CircuitClosed, Failures: 0},
+	}, events)
+
+	// no requests should have been made while the breaker was open
+	require.Len(t, requestTimes, 4)
+	require.True(t, requestTimes[2].Sub(requestTimes[1]) >= cooldown)
+	require.True(t, requestTimes[3].Sub(requestTimes[2]) >= cooldown)
+}
+
+func TestStreamService_Actions_Moderator(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/about/log", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "mod1", r.URL.Query().Get("mod"))
+
+		// mimic Reddit, which only returns the actions of the requested moderator
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{
+						"kind": "modaction",
+						"data": {
+							"id": "ModAction_1",
+							"mod": "mod1"
+						}
+					},
+					{
+						"kind": "modaction",
+						"data": {
+							"id": "ModAction_2",
+							"mod": "mod1"
+						}
+					}
+				]
+			}
+		}`)
+	})
+
+	actions, errs, stop := client.Stream.Actions(context.Background(), "testsubreddit", WithStreamInterval[*ModAction](time.Millisecond*10), WithStreamMaxRequests[*ModAction](2), WithStreamModerator("mod1"))
+	defer stop()
+
+	var received []string
+
+loop:
+	for {
+		select {
+		case action, ok := <-actions:
+			if !ok {
+				break loop
+			}
+			require.Equal(t, "mod1", action.Moderator)
+			received = append(received, action.ID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"ModAction_1", "ModAction_2"}, received)
+}
+
+func TestStreamService_Reported_Compaction(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		// the same post keeps getting reported in quick succession
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{
+						"kind": "t3",
+						"data": {
+							"id": "post1",
+							"name": "t3_post1",
+							"num_reports": %d
+						}
+					}
+				]
+			}
+		}`, counter+1)
+	})
+
+	posts, comments, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit",
+		WithStreamInterval[Streamable](time.Millisecond*10),
+		WithStreamMaxRequests[Streamable](3),
+		WithStreamCompaction[Streamable](time.Hour),
+	)
+	defer stop()
+
+	var received []*Post
+
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			received = append(received, post)
+		case _, ok := <-comments:
+			if !ok {
+				break loop
+			}
+			t.Fatal("unexpected comment")
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Len(t, received, 1)
+	require.Equal(t, "t3_post1", received[0].FullID)
+	require.Equal(t, 3, received[0].NumReports)
+}
+
+func TestStreamService_Reported_TypedHighWaterMark(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		// the post gets reported again during the second run, after it was fetched once
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"id": "post1", "name": "t3_post1", "num_reports": %d}}
+				]
+			}
+		}`, counter/3+1)
+	})
+
+	// the reports that were handled, across runs of the stream
+	type report struct {
+		ID         string
+		NumReports int
+	}
+	handled := NewHighWaterMarkG[report](10)
+
+	var received []report
+	for run := 0; run < 2; run++ {
+		posts, _, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit",
+			WithStreamInterval[Streamable](time.Millisecond*10),
+			WithStreamMaxRequests[Streamable](2),
+		)
+
+	loop:
+		for {
+			select {
+			case post, ok := <-posts:
+				if !ok {
+					break loop
+				}
+				// a new run of the stream streams the post again
+				key := report{post.FullID, post.NumReports}
+				if handled.Contains(key) {
+					continue
+				}
+				handled.Push(key)
+				received = append(received, key)
+			case err, ok := <-errs:
+				if !ok {
+					break loop
+				}
+				require.NoError(t, err)
+			}
+		}
+		stop()
+	}
+
+	require.Equal(t, []report{{"t3_post1", 1}, {"t3_post1", 2}}, received)
+	require.Equal(t, report{"t3_post1", 2}, handled.Top())
+}
+
+func TestStreamService_Reported_Seen(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_x", "num_reports": 1}},
+			{"kind": "t3", "data": {"name": "t3_y", "num_reports": 1}},
+			{"kind": "t1", "data": {"name": "t1_z", "num_reports": 1}}
+		]}}`)
+	})
+
+	collect := func(opts ...StreamOpt[Streamable]) []string {
+		opts = append(opts, WithStreamInterval[Streamable](time.Millisecond*10), WithStreamMaxRequests[Streamable](1))
+		posts, comments, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit", opts...)
+		defer stop()
+
+		var ids []string
+		for posts != nil || comments != nil || errs != nil {
+			select {
+			case post, ok := <-posts:
+				if !ok {
+					posts = nil
+					continue
+				}
+				ids = append(ids, post.FullID)
+			case comment, ok := <-comments:
+				if !ok {
+					comments = nil
+					continue
+				}
+				ids = append(ids, comment.FullID)
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				require.NoError(t, err)
+			}
+		}
+		return ids
+	}
+
+	t.Run("controller", func(t *testing.T) {
+		controller := NewStreamController()
+		controller.MarkSeen("t3_x")
+		require.Equal(t, []string{"t3_y", "t1_z"}, collect(WithStreamController[Streamable](controller)))
+	})
+
+	t.Run("dedup store", func(t *testing.T) {
+		store := NewLRUDedupStore(10)
+		store.Push("t1_z")
+		require.Equal(t, []string{"t3_x", "t3_y"}, collect(WithStreamDedupStore[Streamable](store)))
+	})
+}
+
+func TestStreamService_Reported_ReportsDismissed(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		// the post gets reported twice, then one report is dismissed, and then it gets reported again
+		numReports := []int{1, 2, 1, 3}[counter]
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{
+						"kind": "t3",
+						"data": {
+							"id": "post1",
+							"name": "t3_post1",
+							"num_reports": %d
+						}
+					}
+				]
+			}
+		}`, numReports)
+	})
+
+	posts, comments, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit",
+		WithStreamInterval[Streamable](time.Millisecond*10),
+		WithStreamMaxRequests[Streamable](4),
+	)
+	defer stop()
+
+	var received []int
+
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			received = append(received, post.NumReports)
+		case _, ok := <-comments:
+			if !ok {
+				break loop
+			}
+			t.Fatal("unexpected comment")
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []int{1, 2, 3}, received)
+}
+
+func TestStreamService_Reported_ManyReportedItems(t *testing.T) {
+	client, mux := setup(t)
+
+	// more items than the default mark could hold if every count of every item took up room in it
+	const numPosts = 120
+	numReports := func(i int) int { return 10 }
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		children := make([]string, numPosts)
+		for i := range children {
+			children[i] = fmt.Sprintf(`{"kind": "t3", "data": {"name": "t3_post%d", "num_reports": %d}}`, i, numReports(i))
+		}
+		fmt.Fprintf(w, `{"kind": "Listing", "data": {"children": [%s]}}`, strings.Join(children, ","))
+	})
+
+	mark := NewHighWaterMark(defaultHighWaterMarkCapacity)
+	stream := func() []string {
+		posts, comments, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit",
+			WithStreamInterval[Streamable](time.Millisecond*10),
+			WithStreamMaxRequests[Streamable](3),
+			WithExistingHighWaterMark[Streamable](mark),
+		)
+		defer stop()
+
+		var ids []string
+		for posts != nil || comments != nil || errs != nil {
+			select {
+			case post, ok := <-posts:
+				if !ok {
+					posts = nil
+					continue
+				}
+				ids = append(ids, post.FullID)
+			case _, ok := <-comments:
+				if !ok {
+					comments = nil
+					continue
+				}
+				t.Fatal("unexpected comment")
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				require.NoError(t, err)
+			}
+		}
+		return ids
+	}
+
+	// fetching the same items again doesn't stream them again
+	require.Len(t, stream(), numPosts)
+
+	// neither does a stream resumed from the mark, once some reports were dismissed,
+	// but a post that got more reports than it ever had is streamed again
+	numReports = func(i int) int {
+		if i == 7 {
+			return 11
+		}
+		return 5
+	}
+	require.Equal(t, []string{"t3_post7"}, stream())
+}
+
+func TestStreamService_Posts_SubredditMetadata(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{
+						"kind": "t3",
+						"data": {
+							"name": "t3_post1",
+							"subreddit": "testsubreddit",
+							"subreddit_name_prefixed": "r/testsubreddit",
+							"subreddit_id": "t5_test",
+							"subreddit_subscribers": 12345
+						}
+					}
+				]
+			}
+		}`)
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit", WithStreamInterval[*Post](time.Millisecond*10), WithStreamMaxRequests[*Post](1))
+	defer stop()
+
+	select {
+	case post := <-posts:
+		require.Equal(t, "testsubreddit", post.SubredditName)
+		require.Equal(t, "t5_test", post.SubredditID)
+		require.Equal(t, 12345, post.GetSubredditSubscribers())
+		require.Equal(t, "r/testsubreddit", post.GetSubredditNamePrefixed())
+	case err := <-errs:
+		require.NoError(t, err)
+	}
+
+	var nilPost *Post
+	require.Equal(t, 0, nilPost.GetSubredditSubscribers())
+	require.Equal(t, "", nilPost.GetSubredditNamePrefixed())
+}
+
+func TestStreamService_Posts_TriggerFetch(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{
+						"kind": "t3",
+						"data": {
+							"name": "t3_post%d"
+						}
+					}
+				]
+			}
+		}`, counter+1)
+	})
+
+	// triggering a stream that runs on the real clock does nothing
+	NewStreamController().TriggerFetch()
+
+	clock := NewFakeClock(time.Now())
+	controller := NewStreamController()
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamClock[*Post](clock),
+		WithStreamController[*Post](controller),
+		WithStreamMaxRequests[*Post](2),
+	)
+	defer stop()
+
+	for _, expected := range []string{"t3_post1", "t3_post2"} {
+		controller.TriggerFetch()
+		select {
+		case post := <-posts:
+			require.Equal(t, expected, post.FullID)
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the triggered fetch")
+		}
+	}
+
+	_, ok := <-posts
+	require.False(t, ok)
+}
+
+func TestStreamService_Posts_MinAge(t *testing.T) {
+	client, mux := setup(t)
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{
+						"kind": "t3",
+						"data": {
+							"name": "t3_fresh",
+							"created_utc": %d
+						}
+					},
+					{
+						"kind": "t3",
+						"data": {
+							"name": "t3_old",
+							"created_utc": %d
+						}
+					}
+				]
+			}
+		}`, created.Unix(), created.Add(-time.Hour).Unix())
+	})
+
+	clock := NewFakeClock(created.Add(time.Minute))
+	controller := NewStreamController()
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamClock[*Post](clock),
+		WithStreamController[*Post](controller),
+		WithStreamMaxRequests[*Post](2),
+		WithStreamMinAge[*Post](time.Minute*5),
+	)
+	defer stop()
+
+	receive := func() *Post {
+		select {
+		case post := <-posts:
+			return post
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a post")
+		}
+		return nil
+	}
+
+	// the fresh post is only a minute old, so only the old one comes through
+	controller.TriggerFetch()
+	require.Equal(t, "t3_old", receive().FullID)
+
+	clock.Advance(time.Minute * 5)
+	controller.TriggerFetch()
+	post := receive()
+	require.Equal(t, "t3_fresh", post.FullID)
+	require.Equal(t, time.Minute*6, post.Age(clock.Now()))
+
+	_, ok := <-posts
+	require.False(t, ok)
+}
+
+func TestPostAndComment_Age(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 10, 0, 0, time.UTC)
+	created := &Timestamp{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	require.Equal(t, time.Minute*10, (&Post{Created: created}).Age(now))
+	require.Equal(t, time.Minute*10, (&Comment{Created: created}).Age(now))
+	require.Equal(t, time.Duration(0), (&Post{}).Age(now))
+	require.Equal(t, time.Duration(0), (&Comment{}).Age(now))
+}
+
+func TestStreamService_Posts_SubredditNotFound(t *testing.T) {
+	tests := map[string]struct {
+		body   string
+		reason string
+	}{
+		"not found": {
+			body:   `{"message": "Not Found", "error": 404}`,
+			reason: "does not exist",
+		},
+		"banned": {
+			body:   `{"reason": "banned", "message": "Not Found", "error": 404}`,
+			reason: "banned",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, mux := setup(t)
+
+			var requests int
+			mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodGet, r.Method)
+				requests++
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, test.body)
+			})
+
+			posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit", WithStreamInterval[*Post](time.Millisecond*10))
+			defer stop()
+
+			select {
+			case err := <-errs:
+				require.True(t, errors.Is(err, ErrSubredditNotFound))
+				require.EqualError(t, err, "subreddit not found: r/testsubreddit "+test.reason)
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for the error")
+			}
+
+			// the stream stops on its own
+			select {
+			case _, ok := <-posts:
+				require.False(t, ok)
+			case <-time.After(time.Second):
+				t.Fatal("stream did not stop")
+			}
+			require.Equal(t, 1, requests)
+		})
+	}
+}
+
+func TestStreamService_Reported_SubredditNotFound(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"reason": "banned", "message": "Not Found", "error": 404}`)
+	})
+
+	posts, _, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit", WithStreamInterval[Streamable](time.Millisecond*10))
+	defer stop()
+
+	select {
+	case err := <-errs:
+		require.True(t, errors.Is(err, ErrSubredditNotFound))
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the error")
+	}
+
+	select {
+	case _, ok := <-posts:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("stream did not stop")
+	}
+}
+
+func TestStreamService_NotFoundOutsideSubredditListings(t *testing.T) {
+	client, mux := setup(t)
+
+	notFound := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found", "error": 404}`)
+	}
+	mux.HandleFunc("/message/mentions", notFound)
+	mux.HandleFunc("/api/mod/notes", notFound)
+
+	// the stream still stops, without blaming a subreddit that isn't the one not found
+	for name, stream := range map[string]func() (<-chan error, func()){
+		"mentions": func() (<-chan error, func()) {
+			_, errs, stop := client.Stream.Mentions(context.Background(), WithStreamInterval[*Message](time.Millisecond*10))
+			return errs, stop
+		},
+		"modnotes": func() (<-chan error, func()) {
+			_, errs, stop := client.Stream.Modnotes(context.Background(), "testsubreddit", "testuser", WithStreamInterval[*Modnote](time.Millisecond*10))
+			return errs, stop
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			errs, stop := stream()
+			defer stop()
+
+			select {
+			case err := <-errs:
+				require.False(t, errors.Is(err, ErrSubredditNotFound))
+				require.True(t, IsFatalStreamError(err))
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for the error")
+			}
+
+			select {
+			case _, ok := <-errs:
+				require.False(t, ok)
+			case <-time.After(time.Second):
+				t.Fatal("stream did not stop")
+			}
+		})
+	}
+}
+
+func TestStreamService_Posts_Prefetch(t *testing.T) {
+	// without prefetching, the stream can't fetch again until the consumer has received the whole page
+	for name, prefetch := range map[string]bool{"prefetch": true, "no prefetch": false} {
+		t.Run(name, func(t *testing.T) {
+			client, mux := setup(t)
+
+			// every page has two new posts, followed by one that was on the previous page
+			requests := make(chan int, 3)
+			var count int
+			mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodGet, r.Method)
+				count++
+				fmt.Fprintf(w, `{
+					"kind": "Listing",
+					"data": {
+						"children": [
+							{"kind": "t3", "data": {"name": "t3_%d"}},
+							{"kind": "t3", "data": {"name": "t3_%d"}},
+							{"kind": "t3", "data": {"name": "t3_%d"}}
+						]
+					}
+				}`, count*2, count*2-1, count*2-2)
+				requests <- count
+			})
+
+			opts := []StreamOpt[*Post]{
+				WithStreamClock[*Post](NewFakeClock(time.Now())),
+				WithStreamMaxRequests[*Post](2),
+			}
+			if prefetch {
+				opts = append(opts, WithStreamPrefetch[*Post]())
+			}
+			controller := NewStreamController()
+			posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+				append(opts, WithStreamController[*Post](controller))...,
+			)
+			defer stop()
+
+			waitForRequest := func(expected int) {
+				select {
+				case n := <-requests:
+					require.Equal(t, expected, n)
+				case <-time.After(time.Second):
+					t.Fatalf("timed out waiting for request %d", expected)
+				}
+			}
+
+			controller.TriggerFetch()
+			waitForRequest(1)
+
+			var ids []string
+			select {
+			case post := <-posts:
+				ids = append(ids, post.FullID)
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for a post")
+			}
+
+			// most of the first page hasn't been received yet, and only a prefetching stream
+			// makes the next request anyway
+			controller.TriggerFetch()
+			if prefetch {
+				waitForRequest(2)
+			} else {
+				select {
+				case n := <-requests:
+					t.Fatalf("request %d was made before the first page was received", n)
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+
+			for post := range posts {
+				ids = append(ids, post.FullID)
+			}
+			require.Equal(t, []string{"t3_2", "t3_1", "t3_0", "t3_4", "t3_3"}, ids)
+			if !prefetch {
+				waitForRequest(2)
+			}
+
+			select {
+			case err, ok := <-errs:
+				require.False(t, ok, "unexpected error: %v", err)
+			default:
+			}
+		})
+	}
+}
+
+func TestStreamService_TopPosts(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/top", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "week", r.Form.Get("t"))
+		require.Equal(t, "100", r.Form.Get("limit"))
+		require.Empty(t, r.Form.Get("before"))
+		defer func() { counter++ }()
+
+		switch counter {
+		case 0:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post1", "created_utc": 1577836800}},
+						{"kind": "t3", "data": {"name": "t3_post2", "created_utc": 1577836700}}
+					]
+				}
+			}`)
+		default:
+			// a post that was already streamed is still at the top, but a new one made it in below it
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post1", "created_utc": 1577836800}},
+						{"kind": "t3", "data": {"name": "t3_post3", "created_utc": 1577836600}},
+						{"kind": "t3", "data": {"name": "t3_post2", "created_utc": 1577836700}}
+					]
+				}
+			}`)
+		}
+	})
+
+	posts, errs, stop := client.Stream.TopPosts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+		WithStreamTime("week"),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+	require.Equal(t, []string{"t3_post1", "t3_post2", "t3_post3"}, ids)
+}
+
+func TestWithStreamTime(t *testing.T) {
+	c := NewStreamConfig[*Post]()
+	WithStreamTime("month")(c)
+	require.Equal(t, "month", c.Time)
+
+	WithStreamTime("fortnight")(c)
+	require.Equal(t, "month", c.Time)
+}
+
+func TestStreamService_Posts_MarkSeen(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		switch counter {
+		case 0:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post1"}}
+					]
+				}
+			}`)
+		default:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post5"}},
+						{"kind": "t3", "data": {"name": "t3_post4"}},
+						{"kind": "t3", "data": {"name": "t3_post3"}},
+						{"kind": "t3", "data": {"name": "t3_post2"}},
+						{"kind": "t3", "data": {"name": "t3_post1"}}
+					]
+				}
+			}`)
+		}
+	})
+
+	clock := NewFakeClock(time.Now())
+	controller := NewStreamController()
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamClock[*Post](clock),
+		WithStreamController[*Post](controller),
+		WithStreamMaxRequests[*Post](2),
+	)
+	defer stop()
+
+	receive := func() *Post {
+		select {
+		case post := <-posts:
+			return post
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a post")
+		}
+		return nil
+	}
+
+	controller.TriggerFetch()
+	require.Equal(t, "t3_post1", receive().FullID)
+
+	// other components processed some posts in the meantime
+	var wg sync.WaitGroup
+	for _, id := range []string{"t3_post2", "t3_post4"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			controller.MarkSeen(id)
+		}(id)
+	}
+	wg.Wait()
+
+	controller.TriggerFetch()
+	var ids []string
+	for post := range posts {
+		ids = append(ids, post.FullID)
+	}
+	require.Equal(t, []string{"t3_post5", "t3_post3"}, ids)
+}
+
+func TestStreamService_Posts_MarkSeenConcurrently(t *testing.T) {
+	client, mux := setup(t)
+
+	const markers, perMarker = 4, 25
+	var marked int32
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+
+		// the posts the other components processed only show up once they're all marked
+		children := []string{`{"kind": "t3", "data": {"name": "t3_post1"}}`}
+		if atomic.LoadInt32(&marked) == 1 {
+			children = children[:0]
+			for i := 0; i < markers; i++ {
+				for j := 0; j < perMarker; j++ {
+					children = append(children, fmt.Sprintf(`{"kind": "t3", "data": {"name": "t3_marked%d_%d"}}`, i, j))
+				}
+			}
+			children = append(children, `{"kind": "t3", "data": {"name": "t3_unmarked"}}`)
+		}
+		fmt.Fprintf(w, `{"kind": "Listing", "data": {"children": [%s]}}`, strings.Join(children, ","))
+	})
+
+	controller := NewStreamController()
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond),
+		WithStreamController[*Post](controller),
+	)
+	defer stop()
+
+	select {
+	case post := <-posts:
+		require.Equal(t, "t3_post1", post.FullID)
+	case err := <-errs:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a post")
+	}
+
+	// the stream keeps fetching while the IDs are marked
+	var wg sync.WaitGroup
+	for i := 0; i < markers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perMarker; j++ {
+				controller.MarkSeen(fmt.Sprintf("t3_marked%d_%d", i, j))
+				time.Sleep(time.Millisecond / 4)
+			}
+		}(i)
+	}
+	wg.Wait()
+	atomic.StoreInt32(&marked, 1)
+
+	var ids []string
+	for {
+		select {
+		case post := <-posts:
+			ids = append(ids, post.FullID)
+			if post.FullID == "t3_unmarked" {
+				require.Equal(t, []string{"t3_unmarked"}, ids)
+				return
+			}
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for the unmarked post, got %v", ids)
+		}
+	}
+}
+
+func TestStreamService_PostLifecycle(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		switch counter {
+		case 0:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post1", "author": "test"}}
+					]
+				}
+			}`)
+		default:
+			// post1 got removed, so it's no longer in the listing
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post2", "author": "test"}}
+					]
+				}
+			}`)
+		}
+	})
+	mux.HandleFunc("/by_id/t3_post1", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post1", "author": "test", "selftext": "[removed]", "removed_by_category": "moderator"}}
+				]
+			}
+		}`)
+	})
+	mux.HandleFunc("/by_id/t3_post1,t3_post2", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post1", "author": "test", "selftext": "[removed]", "removed_by_category": "moderator"}},
+					{"kind": "t3", "data": {"name": "t3_post2", "author": "[deleted]", "selftext": "[deleted]", "removed_by_category": "deleted"}}
+				]
+			}
+		}`)
+	})
+
+	events, errs, stop := client.Stream.PostLifecycle(context.Background(), "testsubreddit",
+		WithStreamInterval[*PostEvent](time.Millisecond*10),
+		WithStreamMaxRequests[*PostEvent](3),
+	)
+	defer stop()
+
+	var received []string
+loop:
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				break loop
+			}
+			received = append(received, fmt.Sprintf("%s %s", event.Post.FullID, event.Kind))
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{
+		"t3_post1 created",
+		"t3_post1 removed",
+		"t3_post2 created",
+		"t3_post2 deleted",
+	}, received)
+}
+
+func TestStreamService_Posts_MaxConsecutiveEmpty(t *testing.T) {
+	tests := map[string][]StreamOpt[*Post]{
+		"default":    nil,
+		"dumb logic": {WithDumbLogic[*Post]()},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, mux := setup(t)
+
+			var counter int
+			mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodGet, r.Method)
+				require.NoError(t, r.ParseForm())
+				defer func() { counter++ }()
+
+				switch {
+				case r.Form.Get("before") == "t3_post2":
+					fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+				case counter < 2:
+					fmt.Fprint(w, `{
+						"kind": "Listing",
+						"data": {
+							"children": [
+								{"kind": "t3", "data": {"name": "t3_post2"}},
+								{"kind": "t3", "data": {"name": "t3_post1"}}
+							]
+						}
+					}`)
+				default:
+					fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+				}
+			})
+
+			opts = append(opts, WithStreamInterval[*Post](time.Millisecond*10), WithStreamMaxConsecutiveEmpty[*Post](2))
+			posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit", opts...)
+			defer stop()
+
+			var ids []string
+		loop:
+			for {
+				select {
+				case post, ok := <-posts:
+					if !ok {
+						break loop
+					}
+					ids = append(ids, post.FullID)
+				case err, ok := <-errs:
+					if !ok {
+						break loop
+					}
+					require.NoError(t, err)
+				case <-time.After(time.Second):
+					t.Fatal("stream did not stop")
+				}
+			}
+
+			// one fetch with new posts, then two where they were all seen already, or that were empty
+			require.Equal(t, []string{"t3_post2", "t3_post1"}, ids)
+			require.Equal(t, 3, counter)
+		})
+	}
+}
+
+func TestStreamService_Comments_MinScore(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/comments", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t1", "data": {"name": "t1_comment1", "score": 10}},
+					{"kind": "t1", "data": {"name": "t1_comment2", "score": 1, "score_hidden": true}},
+					{"kind": "t1", "data": {"name": "t1_comment3", "score": 1}}
+				]
+			}
+		}`)
+	})
+
+	comments, errs, stop := client.Stream.Comments(context.Background(), "testsubreddit",
+		WithStreamInterval[*Comment](time.Millisecond*10),
+		WithStreamMaxRequests[*Comment](1),
+		WithStreamMinScore[*Comment](5),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case comment, ok := <-comments:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, comment.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	// the hidden score of comment2 isn't its real one, so it doesn't get dropped
+	require.Equal(t, []string{"t1_comment1", "t1_comment2"}, ids)
+}
+
+func TestStreamService_Posts_InvalidConfig(t *testing.T) {
+	getPosts := func(context.Context, string, string) ([]*Post, error) {
+		t.Fatal("no requests should be made")
+		return nil, nil
+	}
+	tests := map[string][]StreamOpt[*Post]{
+		"min age with dumb logic": {
+			WithDumbLogic[*Post](),
+			WithStreamMinAge[*Post](time.Minute),
+		},
+		"first page limit with dumb logic": {
+			WithDumbLogic[*Post](),
+			WithStreamFirstPageLimit[*Post](200),
+		},
+		"first page limit with a get func": {
+			WithGetFunc(getPosts),
+			WithStreamFirstPageLimit[*Post](200),
+		},
+		"error channel policy with an error handler": {
+			WithStreamErrorHandler[*Post](func(error) {}),
+			WithErrorChannelPolicy[*Post](ErrorPolicyDropNewest),
+		},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, mux := setup(t)
+
+			mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("no requests should be made")
+			})
+
+			opts = append(opts, WithStreamInterval[*Post](time.Millisecond*10))
+
+			// the error handler is set after the options, so the error is handed to it in every case
+			handled := make(chan error, 1)
+			opts = append(opts, WithStreamErrorHandler[*Post](func(err error) { handled <- err }))
+			posts, _, stop := client.Stream.Posts(context.Background(), "testsubreddit", opts...)
+			defer stop()
+
+			select {
+			case err := <-handled:
+				require.True(t, errors.Is(err, ErrInvalidStreamConfig))
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for the error")
+			}
+
+			_, ok := <-posts
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestStreamService_Posts_RedundantConfig(t *testing.T) {
+	// the stream stops at its max requests before the other limits come into play,
+	// which is harmless, so it only gets logged
+	tests := map[string]StreamOpt[*Post]{
+		"max consecutive empty above max requests":     WithStreamMaxConsecutiveEmpty[*Post](3),
+		"circuit breaker threshold above max requests": WithStreamCircuitBreaker[*Post](5, time.Minute),
+	}
+
+	for name, opt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, mux := setup(t)
+
+			var requests int32
+			mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+			})
+
+			var warnings int32
+			posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+				WithStreamInterval[*Post](time.Millisecond*10),
+				WithStreamMaxRequests[*Post](2),
+				opt,
+				WithStreamLogger[*Post](func(level, msg string, kv ...any) {
+					if level == "warn" {
+						atomic.AddInt32(&warnings, 1)
+					}
+				}),
+			)
+			defer stop()
+
+			for posts != nil || errs != nil {
+				select {
+				case _, ok := <-posts:
+					if !ok {
+						posts = nil
+					}
+				case err, ok := <-errs:
+					if !ok {
+						errs = nil
+						continue
+					}
+					require.NoError(t, err)
+				case <-time.After(time.Second):
+					t.Fatal("stream did not stop")
+				}
+			}
+			require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+			require.Equal(t, int32(1), atomic.LoadInt32(&warnings))
+		})
+	}
+}
+
+func TestStreamService_Reported_InvalidConfig(t *testing.T) {
+	client, _ := setup(t)
+
+	_, _, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit",
+		WithDumbLogic[Streamable](),
+		WithStreamFirstPageLimit[Streamable](200),
+	)
+	defer stop()
+
+	err := <-errs
+	require.EqualError(t, err, "invalid stream config: the first page limit is ignored with the dumb logic")
+}
+
+func TestStreamService_Posts_Cursor(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		switch counter {
+		case 0:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post2", "created_utc": 1577836900}},
+						{"kind": "t3", "data": {"name": "t3_post1", "created_utc": 1577836800}}
+					]
+				}
+			}`)
+		default:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post3", "created_utc": 1577837000}}
+					]
+				}
+			}`)
+		}
+	})
+
+	clock := NewFakeClock(time.Now())
+	controller := NewStreamController()
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamClock[*Post](clock),
+		WithStreamController[*Post](controller),
+		WithStreamMaxRequests[*Post](2),
+	)
+	defer stop()
+
+	receive := func() *Post {
+		select {
+		case post := <-posts:
+			return post
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a post")
+		}
+		return nil
+	}
+
+	// the cursor moves right after the item is received, so it's only checked for a little while
+	expectCursor := func(expected string) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for controller.Cursor() != expected && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		require.Equal(t, expected, controller.Cursor())
+	}
+
+	require.Empty(t, controller.Cursor())
+
+	// the first page wasn't received in full yet
+	controller.TriggerFetch()
+	require.Equal(t, "t3_post2", receive().FullID)
+	require.Empty(t, controller.Cursor())
+
+	// post1 is older, so the cursor moves to post2
+	require.Equal(t, "t3_post1", receive().FullID)
+	expectCursor("t3_post2")
+
+	controller.TriggerFetch()
+	require.Equal(t, "t3_post3", receive().FullID)
+	expectCursor("t3_post3")
+}
+
+func TestStreamService_Posts_CursorResume(t *testing.T) {
+	client, mux := setup(t)
+
+	responses := []string{
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_a", "created_utc": 1577836800}}
+		]}}`,
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_d", "created_utc": 1577837100}},
+			{"kind": "t3", "data": {"name": "t3_c", "created_utc": 1577837000}},
+			{"kind": "t3", "data": {"name": "t3_b", "created_utc": 1577836900}},
+			{"kind": "t3", "data": {"name": "t3_a", "created_utc": 1577836800}}
+		]}}`,
+	}
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+		if counter >= len(responses) {
+			counter = len(responses) - 1
+		}
+		fmt.Fprint(w, responses[counter])
+	})
+
+	clock := NewFakeClock(time.Now())
+	controller := NewStreamController()
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamClock[*Post](clock),
+		WithStreamController[*Post](controller),
+	)
+
+	receive := func() string {
+		t.Helper()
+		select {
+		case post := <-posts:
+			return post.FullID
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a post")
+		}
+		return ""
+	}
+
+	controller.TriggerFetch()
+	require.Equal(t, "t3_a", receive())
+	// the consumer stops in the middle of the second page
+	controller.TriggerFetch()
+	require.Equal(t, "t3_d", receive())
+	stop()
+	require.NoError(t, controller.Wait(context.Background()))
+
+	cursor := controller.Cursor()
+	require.Equal(t, "t3_a", cursor)
+
+	// none of the posts that weren't received are lost, at the cost of emitting t3_d again
+	posts, errs, stop = client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](1),
+		WithStartFromFullID[*Post](cursor),
+	)
+	defer stop()
+
+	var ids []string
+	for posts != nil || errs != nil {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				posts = nil
+				continue
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			require.NoError(t, err)
+		}
+	}
+	require.Equal(t, []string{"t3_d", "t3_c", "t3_b"}, ids)
+}
+
+func TestStreamConfig_RandSource(t *testing.T) {
+	intervals := func() []time.Duration {
+		c := NewStreamConfig[*Post]()
+		for _, opt := range []StreamOpt[*Post]{
+			WithStreamInterval[*Post](time.Second * 5),
+			WithStreamJitter[*Post](time.Second),
+			WithStreamRandSource[*Post](rand.NewSource(42)),
+		} {
+			opt(c)
+		}
+
+		var intervals []time.Duration
+		for i := 0; i < 5; i++ {
+			intervals = append(intervals, c.nextInterval())
+		}
+		return intervals
+	}
+
+	first := intervals()
+	require.Equal(t, first, intervals())
+	for _, interval := range first {
+		require.True(t, interval >= time.Second*5 && interval < time.Second*6, "interval %s out of range", interval)
+	}
+	require.NotEqual(t, first[0], first[1])
+}
+
+func TestStreamService_Posts_SampleRate(t *testing.T) {
+	sample := func() []string {
+		client, mux := setup(t)
+
+		mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+
+			var children []string
+			for i := 20; i > 0; i-- {
+				children = append(children, fmt.Sprintf(`{"kind": "t3", "data": {"name": "t3_post%d"}}`, i))
+			}
+			fmt.Fprintf(w, `{"kind": "Listing", "data": {"children": [%s]}}`, strings.Join(children, ","))
+		})
+
+		posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+			WithStreamInterval[*Post](time.Millisecond*10),
+			WithStreamMaxRequests[*Post](1),
+			WithStreamSampleRate[*Post](0.5),
+			WithStreamRandSource[*Post](rand.NewSource(42)),
+		)
+		defer stop()
+
+		var ids []string
+	loop:
+		for {
+			select {
+			case post, ok := <-posts:
+				if !ok {
+					break loop
+				}
+				ids = append(ids, post.FullID)
+			case err, ok := <-errs:
+				if !ok {
+					break loop
+				}
+				require.NoError(t, err)
+			}
+		}
+		return ids
+	}
+
+	ids := sample()
+	require.NotEmpty(t, ids)
+	require.Less(t, len(ids), 20)
+	require.Equal(t, ids, sample())
+}
+
+func TestStreamService_Posts_StartFromFullID(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		switch counter {
+		case 0:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post4"}},
+						{"kind": "t3", "data": {"name": "t3_post3"}},
+						{"kind": "t3", "data": {"name": "t3_post2"}},
+						{"kind": "t3", "data": {"name": "t3_post1"}}
+					]
+				}
+			}`)
+		default:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post5"}},
+						{"kind": "t3", "data": {"name": "t3_post4"}},
+						{"kind": "t3", "data": {"name": "t3_post3"}},
+						{"kind": "t3", "data": {"name": "t3_post2"}},
+						{"kind": "t3", "data": {"name": "t3_post1"}}
+					]
+				}
+			}`)
+		}
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+		WithStartFromFullID[*Post]("t3_post2"),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	// post2 and the ones before it were streamed before the restart
+	require.Equal(t, []string{"t3_post4", "t3_post3", "t3_post5"}, ids)
+}
+
+func TestStreamService_Posts_HighWaterMarkCapacity(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post3"}},
+					{"kind": "t3", "data": {"name": "t3_post2"}},
+					{"kind": "t3", "data": {"name": "t3_post1"}}
+				]
+			}
+		}`)
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+		WithHighWaterMark[*Post](2),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	// the mark only remembers 2 posts, so post3 was forgotten by the time the next fetch came around,
+	// and re-emitting it pushes out post2, and so on
+	require.Equal(t, []string{"t3_post3", "t3_post2", "t3_post1", "t3_post3", "t3_post2", "t3_post1"}, ids)
+}
+
+func TestStreamService_PostsInto(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post%d"}}
+				]
+			}
+		}`, counter+1)
+	})
+
+	out := make(chan *Post, 10)
+	errs := make(chan error, 10)
+	stop := client.Stream.PostsInto(context.Background(), "testsubreddit", out, errs, WithStreamInterval[*Post](time.Millisecond*10))
+
+	for i := 1; i <= 3; i++ {
+		select {
+		case post := <-out:
+			require.Equal(t, fmt.Sprintf("t3_post%d", i), post.FullID)
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a post")
+		}
+	}
+
+	stop()
+	// nothing is sent into the channels anymore, so they can be closed safely
+	close(out)
+	close(errs)
+	for err := range errs {
+		require.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+	}
+}
+
+func TestStreamService_Posts_DumbLogic(t *testing.T) {
+	client, mux := setup(t)
+
+	var befores []string
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.NoError(t, r.ParseForm())
+		befores = append(befores, r.Form.Get("before"))
+
+		switch r.Form.Get("before") {
+		case "t3_post1":
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post3"}},
+						{"kind": "t3", "data": {"name": "t3_post2"}}
+					]
+				}
+			}`)
+		case "t3_post3":
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post4"}}
+					]
+				}
+			}`)
+		default:
+			fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+		}
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](3),
+		WithDumbLogic[*Post](),
+		WithStartFromFullID[*Post]("t3_post1"),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post3", "t3_post2", "t3_post4"}, ids)
+	// every fetch picks up where the newest post of the previous one left off
+	require.Equal(t, []string{"t3_post1", "t3_post3", "t3_post4"}, befores)
+}
+
+func TestStreamService_Posts_GetFunc(t *testing.T) {
+	client, _ := setup(t)
+
+	var subreddits []string
+	getFunc := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		subreddits = append(subreddits, subreddit)
+		return []*Post{
+			{FullID: "t3_post3"},
+			{FullID: "t3_post2"},
+			{FullID: "t3_post1"},
+		}, nil
+	}
+
+	// no handler is registered, so any request to Reddit would fail
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+		WithGetFunc(getFunc),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post3", "t3_post2", "t3_post1"}, ids)
+	require.Equal(t, []string{"testsubreddit", "testsubreddit"}, subreddits)
+}
+
+func TestStreamService_Posts_FirstPageLimit(t *testing.T) {
+	client, mux := setup(t)
+
+	// post300 is the newest post at first, then post301 comes in
+	newest := 300
+	var requests []string
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.NoError(t, r.ParseForm())
+		requests = append(requests, fmt.Sprintf("limit=%s after=%s", r.Form.Get("limit"), r.Form.Get("after")))
+
+		from := newest
+		if after := r.Form.Get("after"); after != "" {
+			_, err := fmt.Sscanf(after, "t3_post%d", &from)
+			require.NoError(t, err)
+			from--
+		} else {
+			defer func() { newest++ }()
+		}
+
+		var limit int
+		_, err := fmt.Sscanf(r.Form.Get("limit"), "%d", &limit)
+		require.NoError(t, err)
+
+		var children []string
+		for i := from; i > from-limit; i-- {
+			children = append(children, fmt.Sprintf(`{"kind": "t3", "data": {"name": "t3_post%d"}}`, i))
+		}
+		fmt.Fprintf(w, `{"kind": "Listing", "data": {"children": [%s]}}`, strings.Join(children, ","))
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+		WithStreamFirstPageLimit[*Post](250),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Len(t, ids, 251)
+	require.Equal(t, "t3_post300", ids[0])
+	require.Equal(t, "t3_post51", ids[249])
+	require.Equal(t, "t3_post301", ids[250])
+	require.Equal(t, []string{
+		"limit=100 after=",
+		"limit=100 after=t3_post201",
+		"limit=50 after=t3_post101",
+		"limit=100 after=",
+	}, requests)
+}
+
+func TestStreamService_Posts_ContextCanceled(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post1"}}
+				]
+			}
+		}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	posts, errs, stop := client.Stream.Posts(ctx, "testsubreddit", WithStreamInterval[*Post](time.Millisecond*10))
+	defer stop()
+
+	select {
+	case post := <-posts:
+		require.Equal(t, "t3_post1", post.FullID)
+	case err := <-errs:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a post")
+	}
+
+	cancel()
+	select {
+	case err := <-errs:
+		require.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the cancellation error")
+	}
+	_, ok := <-posts
+	require.False(t, ok)
+	_, ok = <-errs
+	require.False(t, ok)
+}
+
+func TestStreamService_Posts_StopWithoutReading(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post%d"}}
+				]
+			}
+		}`, counter+1)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	posts, errs, stop := client.Stream.Posts(ctx, "testsubreddit", WithStreamInterval[*Post](time.Millisecond*10))
+
+	// let the stream get blocked on sending into the unread channels
+	time.Sleep(time.Millisecond * 50)
+	cancel()
+	stop()
+
+	for range posts {
+	}
+	for range errs {
+	}
+}
+
+func TestStreamService_ModQueue(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/modqueue", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		switch counter {
+		case 0, 3:
+			// the items show up, and later come back unchanged after leaving the queue
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 1}},
+						{"kind": "t1", "data": {"name": "t1_comment1", "num_reports": 1}}
+					]
+				}
+			}`)
+		case 1:
+			fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+		case 2:
+			// the post left the queue, while the comment gets reported again (and then a report is dismissed)
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t1", "data": {"name": "t1_comment1", "num_reports": 2}}
+					]
+				}
+			}`)
+		default:
+			// and then the post comes back, once it got removed
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 1, "removed_by_category": "moderator"}},
+						{"kind": "t1", "data": {"name": "t1_comment1", "num_reports": 1}}
+					]
+				}
+			}`)
+		}
+	})
+
+	posts, comments, errs, stop := client.Stream.ModQueue(context.Background(), "testsubreddit",
+		WithStreamInterval[Streamable](time.Millisecond*10),
+		WithStreamMaxRequests[Streamable](5),
+	)
+	defer stop()
+
+	var received []string
+
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			received = append(received, fmt.Sprintf("%s/%d/%s", post.FullID, post.NumReports, post.RemovedByCategory))
+		case comment, ok := <-comments:
+			if !ok {
+				break loop
+			}
+			received = append(received, fmt.Sprintf("%s/%d", comment.FullID, comment.NumReports))
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{
+		"t3_post1/1/",
+		"t1_comment1/1",
+		"t1_comment1/2",
+		"t1_comment1/1",
+		"t3_post1/1/moderator",
+	}, received)
+}
+
+func TestStreamService_ModQueue_PersistedState(t *testing.T) {
+	client, mux := setup(t)
+
+	responses := []string{
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 1}},
+			{"kind": "t1", "data": {"name": "t1_comment1", "num_reports": 1}}
+		]}}`,
+		// the comment got reported again while the stream wasn't running
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 1}},
+			{"kind": "t1", "data": {"name": "t1_comment1", "num_reports": 2}}
+		]}}`,
+	}
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/modqueue", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+		fmt.Fprint(w, responses[counter])
+	})
+
+	run := func(mark HighWaterMark) []string {
+		posts, comments, errs, stop := client.Stream.ModQueue(context.Background(), "testsubreddit",
+			WithStreamInterval[Streamable](time.Millisecond*10),
+			WithStreamMaxRequests[Streamable](1),
+			WithExistingHighWaterMark[Streamable](mark),
+		)
+		defer stop()
+
+		var ids []string
+		for posts != nil || comments != nil || errs != nil {
+			select {
+			case post, ok := <-posts:
+				if !ok {
+					posts = nil
+					continue
+				}
+				ids = append(ids, fmt.Sprintf("%s/%d", post.FullID, post.NumReports))
+			case comment, ok := <-comments:
+				if !ok {
+					comments = nil
+					continue
+				}
+				ids = append(ids, fmt.Sprintf("%s/%d", comment.FullID, comment.NumReports))
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				require.NoError(t, err)
+			}
+		}
+		return ids
+	}
+
+	mark := NewHighWaterMark(defaultHighWaterMarkCapacity)
+	require.Equal(t, []string{"t3_post1/1", "t1_comment1/1"}, run(mark))
+
+	var buf bytes.Buffer
+	require.NoError(t, SaveStreamState(mark, &buf))
+	restored, err := LoadStreamState(&buf)
+	require.NoError(t, err)
+
+	// the restarted stream knows about the states of the items it streamed before
+	require.Equal(t, []string{"t1_comment1/2"}, run(restored))
+}
+
+func TestStreamService_Spam(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/spam", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		switch counter {
+		case 0:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post1"}},
+						{"kind": "t1", "data": {"name": "t1_comment1"}}
+					]
+				}
+			}`)
+		case 1:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post2"}},
+						{"kind": "t1", "data": {"name": "t1_comment2"}}
+					]
+				}
+			}`)
+		default:
+			fmt.Fprint(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_post3"}},
+						{"kind": "t3", "data": {"name": "t3_post2"}},
+						{"kind": "t1", "data": {"name": "t1_comment2"}}
+					]
+				}
+			}`)
+		}
+	})
+
+	posts, comments, errs, stop := client.Stream.Spam(context.Background(), "testsubreddit",
+		WithStreamInterval[Streamable](time.Millisecond*10),
+		WithStreamMaxRequests[Streamable](3),
+		WithStreamDiscardInitial[Streamable](),
+	)
+	defer stop()
+
+	var received []string
+
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			received = append(received, post.FullID)
+		case comment, ok := <-comments:
+			if !ok {
+				break loop
+			}
+			received = append(received, comment.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post2", "t1_comment2", "t3_post3"}, received)
+}
+
+func TestStateTracker(t *testing.T) {
+	tracker := newStateTracker[string](2)
+
+	_, ok := tracker.Record("t3_post1", "a")
+	require.False(t, ok)
+	last, ok := tracker.Record("t3_post1", "b")
+	require.True(t, ok)
+	require.Equal(t, "a", last)
+
+	// seeing post1 again makes post2 the least recently seen one, which goes once post3 comes in
+	tracker.Record("t3_post2", "a")
+	tracker.Record("t3_post1", "b")
+	tracker.Record("t3_post3", "a")
+	_, ok = tracker.Record("t3_post2", "a")
+	require.False(t, ok)
+	last, ok = tracker.Record("t3_post3", "b")
+	require.True(t, ok)
+	require.Equal(t, "a", last)
+	require.Equal(t, 2, tracker.order.Len())
+}
+
+func TestStreamService_Spam_PersistedState(t *testing.T) {
+	client, mux := setup(t)
+
+	responses := []string{
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post1"}}
+		]}}`,
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t1", "data": {"name": "t1_comment1"}},
+			{"kind": "t3", "data": {"name": "t3_post1"}}
+		]}}`,
+	}
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/spam", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+		fmt.Fprint(w, responses[counter])
+	})
+
+	run := func(mark HighWaterMark) []string {
+		posts, comments, errs, stop := client.Stream.Spam(context.Background(), "testsubreddit",
+			WithStreamInterval[Streamable](time.Millisecond*10),
+			WithStreamMaxRequests[Streamable](1),
+			WithExistingHighWaterMark[Streamable](mark),
+		)
+		defer stop()
+
+		var ids []string
+		for posts != nil || comments != nil || errs != nil {
+			select {
+			case post, ok := <-posts:
+				if !ok {
+					posts = nil
+					continue
+				}
+				ids = append(ids, post.FullID)
+			case comment, ok := <-comments:
+				if !ok {
+					comments = nil
+					continue
+				}
+				ids = append(ids, comment.FullID)
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				require.NoError(t, err)
+			}
+		}
+		return ids
+	}
+
+	mark := NewHighWaterMark(defaultHighWaterMarkCapacity)
+	require.Equal(t, []string{"t3_post1"}, run(mark))
+
+	var buf bytes.Buffer
+	require.NoError(t, SaveStreamState(mark, &buf))
+	restored, err := LoadStreamState(&buf)
+	require.NoError(t, err)
+
+	// the restarted stream doesn't stream the post again
+	require.Equal(t, []string{"t1_comment1"}, run(restored))
+}
+
+func TestStreamService_Posts_DedupStore(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post%d"}},
+					{"kind": "t3", "data": {"name": "t3_post1"}}
+				]
+			}
+		}`, counter+2)
+	})
+
+	// the store was already used by another stream
+	store := NewLRUDedupStore(10)
+	store.Push("t3_post2")
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](3),
+		WithStreamDedupStore[*Post](store),
+		WithStreamDedupStore[*Post](nil),
+	)
+	defer stop()
+
+	var received []string
+
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			received = append(received, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post1", "t3_post3", "t3_post4"}, received)
+	for _, id := range []string{"t3_post1", "t3_post2", "t3_post3", "t3_post4"} {
+		require.True(t, store.Contains(id))
+	}
+}
+
+func TestStreamService_Posts_DedupStoreResumed(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_a"}},
+					{"kind": "t3", "data": {"name": "t3_b"}},
+					{"kind": "t3", "data": {"name": "t3_c"}}
+				]
+			}
+		}`)
+	})
+
+	// the mark was streamed before the stream was restarted, so it isn't streamed on the second fetch either
+	store := NewLRUDedupStore(10)
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+		WithHighWaterMark[*Post](10, "t3_b"),
+		WithStreamDedupStore[*Post](store),
+	)
+	defer stop()
+
+	var received []string
+
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			received = append(received, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_a"}, received)
+	for _, id := range []string{"t3_a", "t3_b", "t3_c"} {
+		require.True(t, store.Contains(id))
+	}
+}
+
+func TestStreamService_Edited(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/edited", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		// the post gets edited a second time, and the comment gets edited once in the meantime
+		postEdited := []int{1600000000, 1600000000, 1600000100}[counter]
+		comment := ""
+		if counter > 0 {
+			comment = `, {"kind": "t1", "data": {"name": "t1_comment1", "edited": 1600000050}}`
+		}
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post1", "edited": %d}}%s
+				]
+			}
+		}`, postEdited, comment)
+	})
+
+	posts, comments, errs, stop := client.Stream.Edited(context.Background(), "testsubreddit",
+		WithStreamInterval[Streamable](time.Millisecond*10),
+		WithStreamMaxRequests[Streamable](3),
+	)
+	defer stop()
+
+	var received []string
+
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			received = append(received, fmt.Sprintf("%s@%d", post.FullID, post.Edited.Unix()))
+		case comment, ok := <-comments:
+			if !ok {
+				break loop
+			}
+			received = append(received, fmt.Sprintf("%s@%d", comment.FullID, comment.Edited.Unix()))
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post1@1600000000", "t1_comment1@1600000050", "t3_post1@1600000100"}, received)
+}
+
+func TestStreamService_Posts_Domains(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post1", "domain": "github.com"}},
+					{"kind": "t3", "data": {"name": "t3_post2", "domain": "gist.GitHub.com"}},
+					{"kind": "t3", "data": {"name": "t3_post3", "domain": "notgithub.com"}},
+					{"kind": "t3", "data": {"name": "t3_post4", "domain": "self.testsubreddit"}},
+					{"kind": "t3", "data": {"name": "t3_post5", "domain": "gitlab.com"}},
+					{"kind": "t3", "data": {"name": "t3_post6", "domain": "youtube.com"}}
+				]
+			}
+		}`)
+	})
+
+	store := NewLRUDedupStore(10)
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+		WithStreamDedupStore[*Post](store),
+		WithStreamDomains[*Post]("github.com", ""),
+		WithStreamDomains[*Post]("gitlab.com"),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post1", "t3_post2", "t3_post5"}, ids)
+	// the skipped posts are recorded as seen as well
+	for i := 1; i <= 6; i++ {
+		require.True(t, store.Contains(fmt.Sprintf("t3_post%d", i)))
+	}
+}
+
+func TestStreamController_SetInterval(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post%d"}}
+				]
+			}
+		}`, counter+1)
+	})
+
+	controller := NewStreamController()
+	require.EqualError(t, controller.SetInterval(0), "d: must be greater than 0")
+	require.EqualError(t, controller.SetInterval(-time.Second), "d: must be greater than 0")
+
+	clock := NewFakeClock(time.Now())
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamClock[*Post](clock),
+		WithStreamController[*Post](controller),
+		WithStreamInterval[*Post](time.Minute),
+	)
+	defer stop()
+
+	expectPost := func(expected string) {
+		t.Helper()
+		select {
+		case post := <-posts:
+			require.Equal(t, expected, post.FullID)
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", expected)
+		}
+	}
+	expectNothing := func() {
+		t.Helper()
+		select {
+		case post := <-posts:
+			t.Fatalf("unexpected post %s", post.FullID)
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Millisecond * 50):
+		}
+	}
+
+	clock.Advance(time.Minute)
+	expectPost("t3_post1")
+
+	require.NoError(t, controller.SetInterval(time.Second*10))
+	clock.Advance(time.Second * 10)
+	expectPost("t3_post2")
+	clock.Advance(time.Second * 9)
+	expectNothing()
+	clock.Advance(time.Second)
+	expectPost("t3_post3")
+
+	// slowing it back down
+	require.NoError(t, controller.SetInterval(time.Minute))
+	clock.Advance(time.Second * 30)
+	expectNothing()
+	clock.Advance(time.Second * 30)
+	expectPost("t3_post4")
+}
+
+func TestStreamController_Wait(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+	})
+
+	controller := NewStreamController()
+	_, _, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamController[*Post](controller),
+	)
+
+	// the stream is still running
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, controller.Wait(waitCtx))
+
+	stop()
+	select {
+	case <-controller.Done():
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the stream to be done")
+	}
+	require.NoError(t, controller.Wait(context.Background()))
+}
+
+func TestStreamController_Done_Reported(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+	})
+
+	controller := NewStreamController()
+	posts, comments, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit",
+		WithStreamInterval[Streamable](time.Millisecond*10),
+		WithStreamMaxRequests[Streamable](2),
+		WithStreamController[Streamable](controller),
+	)
+	defer stop()
+
+	select {
+	case <-controller.Done():
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the stream to be done")
+	}
+
+	// by the time it's done, every channel is closed
+	_, ok := <-posts
+	require.False(t, ok)
+	_, ok = <-comments
+	require.False(t, ok)
+	_, ok = <-errs
+	require.False(t, ok)
+}
+
+func TestStreamController_Err(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+	})
+	mux.HandleFunc("/r/notfound/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"reason": "banned", "message": "Not Found", "error": 404}`)
+	})
+
+	// drains the errors until the stream is done, then returns why it ended
+	ended := func(t *testing.T, controller *StreamController, errs <-chan error) error {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for errs != nil {
+			select {
+			case _, ok := <-errs:
+				if !ok {
+					errs = nil
+				}
+			case <-timeout:
+				t.Fatal("timed out waiting for the stream to be done")
+			}
+		}
+		require.NoError(t, controller.Wait(context.Background()))
+		return controller.Err()
+	}
+
+	t.Run("completed", func(t *testing.T) {
+		controller := NewStreamController()
+		_, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+			WithStreamInterval[*Post](time.Millisecond*10),
+			WithStreamMaxRequests[*Post](2),
+			WithStreamController[*Post](controller),
+		)
+		defer stop()
+		require.Equal(t, ErrStreamCompleted, ended(t, controller, errs))
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		controller := NewStreamController()
+		_, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+			WithStreamInterval[*Post](time.Millisecond*10),
+			WithStreamController[*Post](controller),
+		)
+		// still running
+		require.NoError(t, controller.Err())
+		stop()
+		require.Equal(t, ErrStreamStopped, ended(t, controller, errs))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		controller := NewStreamController()
+		_, errs, stop := client.Stream.Posts(ctx, "testsubreddit",
+			WithStreamInterval[*Post](time.Millisecond*10),
+			WithStreamController[*Post](controller),
+		)
+		defer stop()
+		cancel()
+		err := ended(t, controller, errs)
+		require.True(t, errors.Is(err, ErrStreamCancelled))
+		require.EqualError(t, err, "stream cancelled: context canceled")
+	})
+
+	t.Run("fatal", func(t *testing.T) {
+		controller := NewStreamController()
+		_, _, errs, stop := client.Stream.Reported(context.Background(), "notfound",
+			WithStreamInterval[Streamable](time.Millisecond*10),
+			WithStreamController[Streamable](controller),
+		)
+		defer stop()
+		err := ended(t, controller, errs)
+		require.True(t, IsFatalStreamError(err))
+		require.True(t, errors.Is(err, ErrSubredditNotFound))
+	})
+}
+
+func TestStreamService_CancelledWithoutReader(t *testing.T) {
+	client, mux := setup(t)
+
+	empty := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+	}
+	mux.HandleFunc("/r/testsubreddit/new", empty)
+	mux.HandleFunc("/message/unread", empty)
+
+	// the consumer cancels the context without ever reading the errors or calling stop,
+	// so the stream only exits once it gave up on sending ctx.Err()
+	expectDone := func(t *testing.T, controller *StreamController, cancel context.CancelFunc) {
+		time.Sleep(time.Millisecond * 30)
+		cancel()
+		select {
+		case <-controller.Done():
+		case <-time.After(cancelledSendGrace * 2):
+			t.Fatal("timed out waiting for the stream to be done")
+		}
+		require.True(t, errors.Is(controller.Err(), ErrStreamCancelled))
+	}
+
+	t.Run("posts", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		controller := NewStreamController()
+		client.Stream.Posts(ctx, "testsubreddit",
+			WithStreamInterval[*Post](time.Millisecond*10),
+			WithStreamController[*Post](controller),
+		)
+		expectDone(t, controller, cancel)
+	})
+
+	// the grace period doesn't wait for a fake clock to be advanced
+	t.Run("fake clock", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		controller := NewStreamController()
+		client.Stream.Posts(ctx, "testsubreddit",
+			WithStreamClock[*Post](NewFakeClock(time.Now())),
+			WithStreamController[*Post](controller),
+		)
+		expectDone(t, controller, cancel)
+	})
+
+	t.Run("inbox unread", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		controller := NewStreamController()
+		client.Stream.InboxUnread(ctx,
+			WithStreamInterval[*Message](time.Millisecond*10),
+			WithStreamController[*Message](controller),
+		)
+		expectDone(t, controller, cancel)
+	})
+
+	// MultiPosts can't have a controller, but its channels get closed once it gave up on forwarding ctx.Err()
+	t.Run("multi posts", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, errs, _ := client.Stream.MultiPosts(ctx, []string{"testsubreddit"},
+			WithStreamInterval[*Post](time.Millisecond*10),
+		)
+		time.Sleep(time.Millisecond * 30)
+		cancel()
+		time.Sleep(cancelledSendGrace + time.Millisecond*200)
+		select {
+		case err, ok := <-errs:
+			require.False(t, ok, "the error was still being forwarded: %v", err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the errors channel to be closed")
+		}
+	})
+}
+
+func TestStreamService_StopWhileSending(t *testing.T) {
+	client, mux := setup(t)
+
+	listing := func(children string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			fmt.Fprintf(w, `{"kind": "Listing", "data": {"children": [%s]}}`, children)
+		}
+	}
+	mux.HandleFunc("/r/testsubreddit/new", listing(`
+		{"kind": "t3", "data": {"name": "t3_post2"}},
+		{"kind": "t3", "data": {"name": "t3_post1"}}
+	`))
+	mux.HandleFunc("/r/testsubreddit/about/reports", listing(`
+		{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 1}},
+		{"kind": "t1", "data": {"name": "t1_comment1", "num_reports": 1}}
+	`))
+	mux.HandleFunc("/message/unread", listing(`
+		{"kind": "t1", "data": {"id": "c1", "name": "t1_c1", "was_comment": true, "new": true}},
+		{"kind": "t4", "data": {"id": "m1", "name": "t4_m1", "was_comment": false, "new": true}}
+	`))
+
+	// nothing reads from the channels, so the streams are stuck sending their first item when they get stopped
+	expectDone := func(controller *StreamController, stop func()) {
+		t.Helper()
+		time.Sleep(time.Millisecond * 50)
+		stop()
+		select {
+		case <-controller.Done():
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the stream to be done")
+		}
+	}
+
+	t.Run("posts", func(t *testing.T) {
+		controller := NewStreamController()
+		_, _, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+			WithStreamInterval[*Post](time.Millisecond*10),
+			WithStreamController[*Post](controller),
+		)
+		expectDone(controller, stop)
+	})
+
+	t.Run("reported", func(t *testing.T) {
+		controller := NewStreamController()
+		_, _, _, stop := client.Stream.Reported(context.Background(), "testsubreddit",
+			WithStreamInterval[Streamable](time.Millisecond*10),
+			WithStreamController[Streamable](controller),
+		)
+		expectDone(controller, stop)
+	})
+
+	t.Run("inbox unread", func(t *testing.T) {
+		controller := NewStreamController()
+		_, _, _, stop := client.Stream.InboxUnread(context.Background(),
+			WithStreamInterval[*Message](time.Millisecond*10),
+			WithStreamController[*Message](controller),
+		)
+		expectDone(controller, stop)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		controller := NewStreamController()
+		_, errs, stop := client.Stream.Posts(ctx, "testsubreddit",
+			WithStreamInterval[*Post](time.Millisecond*10),
+			WithStreamController[*Post](controller),
+		)
+		defer stop()
+
+		time.Sleep(time.Millisecond * 50)
+		cancel()
+		timeout := time.After(time.Second)
+		for errs != nil {
+			select {
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				require.True(t, errors.Is(err, context.Canceled))
+			case <-timeout:
+				t.Fatal("timed out waiting for the stream to be done")
+			}
+		}
+		<-controller.Done()
+	})
+}
+
+func TestStreamService_Unmoderated(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/unmoderated", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		// post1 gets moderated and falls out of the listing, then shows up again
+		children := []string{
+			`{"kind": "t3", "data": {"name": "t3_post2"}}, {"kind": "t3", "data": {"name": "t3_post1"}}`,
+			`{"kind": "t3", "data": {"name": "t3_post2"}}`,
+			`{"kind": "t3", "data": {"name": "t3_post3"}}, {"kind": "t3", "data": {"name": "t3_post2"}}, {"kind": "t3", "data": {"name": "t3_post1"}}`,
+		}[counter]
+		fmt.Fprintf(w, `{"kind": "Listing", "data": {"children": [%s]}}`, children)
+	})
+
+	posts, errs, stop := client.Stream.Unmoderated(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](3),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post2", "t3_post1", "t3_post3"}, ids)
+}
+
+func TestStreamService_Mentions(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/message/mentions", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		// nobody mentions you for a while in between
+		children := []string{
+			`{"kind": "t1", "data": {"name": "t1_comment1", "author": "user1"}}`,
+			``,
+			``,
+			`{"kind": "t1", "data": {"name": "t1_comment2", "author": "user2"}}, {"kind": "t1", "data": {"name": "t1_comment1", "author": "user1"}}`,
+		}[counter]
+		fmt.Fprintf(w, `{"kind": "Listing", "data": {"children": [%s]}}`, children)
+	})
+
+	mentions, errs, stop := client.Stream.Mentions(context.Background(),
+		WithStreamInterval[*Message](time.Millisecond*10),
+		WithStreamMaxRequests[*Message](4),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case mention, ok := <-mentions:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, mention.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t1_comment1", "t1_comment2"}, ids)
+}
+
+func TestStreamService_Posts_InsufficientScope(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		counter++
+		w.Header().Set("WWW-Authenticate", `Bearer realm="reddit", error="insufficient_scope"`)
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+	)
+	defer stop()
+
+	err := <-errs
+	var scopeErr *InsufficientScopeError
+	require.True(t, errors.As(err, &scopeErr), "unexpected error: %v", err)
+	require.Empty(t, scopeErr.Scope)
+
+	// the stream stops instead of retrying
+	_, ok := <-posts
+	require.False(t, ok)
+	require.Equal(t, 1, counter)
+}
+
+func TestStreamService_MultiPosts(t *testing.T) {
+	client, mux := setup(t)
+
+	for _, subreddit := range []string{"sub1", "sub2"} {
+		subreddit := subreddit
+		var counter int
+		mux.HandleFunc("/r/"+subreddit+"/new", func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			defer func() { counter++ }()
+
+			fmt.Fprintf(w, `{
+				"kind": "Listing",
+				"data": {
+					"children": [
+						{"kind": "t3", "data": {"name": "t3_%s_post%d"}}
+					]
+				}
+			}`, subreddit, counter+1)
+		})
+	}
+
+	posts, errs, stop := client.Stream.MultiPosts(context.Background(), []string{"sub1", "sub2"},
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.ElementsMatch(t, []string{"t3_sub1_post1", "t3_sub1_post2", "t3_sub2_post1", "t3_sub2_post2"}, ids)
+	// both channels get closed once the streams are done
+	_, ok := <-posts
+	require.False(t, ok)
+	_, ok = <-errs
+	require.False(t, ok)
+}
+
+func TestStreamService_MultiPosts_RandSource(t *testing.T) {
+	client, mux := setup(t)
+
+	subreddits := []string{"sub1", "sub2", "sub3"}
+	for _, subreddit := range subreddits {
+		subreddit := subreddit
+		mux.HandleFunc("/r/"+subreddit+"/new", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_%s"}}]}}`, subreddit)
+		})
+	}
+
+	// the streams jitter at the same time, each with a source of their own, so that it isn't a data race
+	posts, errs, stop := client.Stream.MultiPosts(context.Background(), subreddits,
+		WithStreamInterval[*Post](time.Millisecond),
+		WithStreamJitter[*Post](time.Millisecond),
+		WithStreamMaxRequests[*Post](10),
+		WithStreamRandSource[*Post](rand.NewSource(1)),
+	)
+	defer stop()
+
+	var ids []string
+	for posts != nil || errs != nil {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				posts = nil
+				continue
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			require.NoError(t, err)
+		}
+	}
+	require.ElementsMatch(t, []string{"t3_sub1", "t3_sub2", "t3_sub3"}, ids)
+}
+
+func TestStreamService_MultiPosts_Controller(t *testing.T) {
+	client, mux := setup(t)
+
+	var requests int32
+	mux.HandleFunc("/r/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	})
+
+	controller := NewStreamController()
+	posts, errs, stop := client.Stream.MultiPosts(context.Background(), []string{"a", "b"},
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamController[*Post](controller),
+	)
+	defer stop()
+
+	err := <-errs
+	require.True(t, IsFatalStreamError(err))
+	require.True(t, errors.Is(err, ErrInvalidStreamConfig))
+	_, ok := <-errs
+	require.False(t, ok)
+	_, ok = <-posts
+	require.False(t, ok)
+
+	// the controller doesn't wait on a stream that never started
+	require.NoError(t, controller.Wait(context.Background()))
+	require.Equal(t, err, controller.Err())
+	require.Zero(t, atomic.LoadInt32(&requests))
+}
+
+func TestStreamService_MultiPosts_Stop(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/sub1/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_post1"}}]}}`)
+	})
+	mux.HandleFunc("/r/sub2/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_post2"}}]}}`)
+	})
+
+	posts, errs, stop := client.Stream.MultiPosts(context.Background(), []string{"sub1", "sub2"},
+		WithStreamInterval[*Post](time.Millisecond*10),
+	)
+
+	// let the streams get stuck sending into the unread channels
+	time.Sleep(time.Millisecond * 50)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		stop()
+		stop()
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("timed out stopping the streams")
+	}
+
+	for range posts {
+	}
+	for range errs {
+	}
+}
+
+func TestStreamService_Posts_MinUpvoteRatio(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post1", "upvote_ratio": 0.95}},
+					{"kind": "t3", "data": {"name": "t3_post2", "upvote_ratio": 0.9}},
+					{"kind": "t3", "data": {"name": "t3_post3", "upvote_ratio": 0.5}},
+					{"kind": "t3", "data": {"name": "t3_post4"}}
+				]
+			}
+		}`)
+	})
+
+	store := NewLRUDedupStore(10)
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](1),
+		WithStreamDedupStore[*Post](store),
+		WithStreamMinUpvoteRatio[*Post](0.9),
+		WithStreamMinUpvoteRatio[*Post](1.5),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	// post4 came back without a ratio, so it isn't skipped
+	require.Equal(t, []string{"t3_post1", "t3_post2", "t3_post4"}, ids)
+	require.True(t, store.Contains("t3_post3"))
+}
+
+func TestStreamService_Buffer(t *testing.T) {
+	client, mux := setup(t)
+
+	listing := `{
+		"kind": "Listing",
+		"data": {
+			"children": [
+				{"kind": "t3", "data": {"name": "t3_post3"}},
+				{"kind": "t3", "data": {"name": "t3_post2"}},
+				{"kind": "t3", "data": {"name": "t3_post1"}}
+			]
+		}
+	}`
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, listing)
+	})
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, listing)
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](1),
+		WithStreamBuffer[*Post](3),
+		WithStreamBuffer[*Post](-1),
+	)
+	defer stop()
+	require.Equal(t, 3, cap(posts))
+	require.Equal(t, 3, cap(errs))
+
+	// the whole page fits in the buffer, so the stream gets through it without anyone reading
+	time.Sleep(time.Millisecond * 50)
+	require.Equal(t, 3, len(posts))
+
+	var ids []string
+	for post := range posts {
+		ids = append(ids, post.FullID)
+	}
+	require.Equal(t, []string{"t3_post3", "t3_post2", "t3_post1"}, ids)
+
+	reported, comments, reportedErrs, stopReported := client.Stream.Reported(context.Background(), "testsubreddit",
+		WithStreamBuffer[Streamable](5),
+	)
+	defer stopReported()
+	require.Equal(t, 5, cap(reported))
+	require.Equal(t, 5, cap(comments))
+	require.Equal(t, 5, cap(reportedErrs))
+
+	unbuffered, _, stopUnbuffered := client.Stream.Posts(context.Background(), "testsubreddit")
+	defer stopUnbuffered()
+	require.Equal(t, 0, cap(unbuffered))
+}
+
+func TestStreamService_Posts_Retry(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		// the first fetch only succeeds on its second retry, the second one never does
+		if counter < 2 || counter > 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_post1"}}]}}`)
+	})
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+		WithStreamRetry[*Post](3, time.Millisecond),
+		WithStreamRetry[*Post](0, time.Second),
+	)
+	defer stop()
+
+	var ids []string
+	var errCount int
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			var errResp *ErrorResponse
+			require.True(t, errors.As(err, &errResp), "unexpected error: %v", err)
+			errCount++
+		}
+	}
+
+	require.Equal(t, []string{"t3_post1"}, ids)
+	// the second fetch and its 3 retries all failed, making for a single error
+	require.Equal(t, 1, errCount)
+	require.Equal(t, 7, counter)
+}
+
+func TestStreamService_Posts_RetryCanceled(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	posts, errs, stop := client.Stream.Posts(ctx, "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamRetry[*Post](3, time.Hour),
+	)
+	defer stop()
+
+	// let the stream start waiting for its first retry
+	time.Sleep(time.Millisecond * 50)
+	cancel()
+
+	select {
+	case err := <-errs:
+		require.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the backoff to be interrupted")
+	}
+	_, ok := <-posts
+	require.False(t, ok)
+}
+
+func TestStreamConfig_RetryBackoff(t *testing.T) {
+	c := NewStreamConfig[*Post]()
+	WithStreamRetry[*Post](10, time.Second*10)(c)
+
+	require.Equal(t, time.Second*10, c.retryBackoff(0))
+	require.Equal(t, time.Second*20, c.retryBackoff(1))
+	require.Equal(t, time.Second*40, c.retryBackoff(2))
+	require.Equal(t, time.Minute, c.retryBackoff(3))
+	require.Equal(t, time.Minute, c.retryBackoff(9))
+}
+
+func TestStreamService_Posts_ContentHash(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post3", "author": "user1", "title": "buy now", "score": 1}},
+					{"kind": "t3", "data": {"name": "t3_post2", "author": "user2", "title": "hello"}},
+					{"kind": "t3", "data": {"name": "t3_post1", "author": "user1", "title": "buy now", "score": 5}}
+				]
+			}
+		}`)
+	})
+
+	for name, hash := range map[string]func(*Post) string{
+		"default": nil,
+		"custom":  func(p *Post) string { return p.Title },
+	} {
+		t.Run(name, func(t *testing.T) {
+			posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+				WithStreamInterval[*Post](time.Millisecond*10),
+				WithStreamMaxRequests[*Post](2),
+				WithStreamContentHash[*Post](hash),
+			)
+			defer stop()
+
+			var ids []string
+		loop:
+			for {
+				select {
+				case post, ok := <-posts:
+					if !ok {
+						break loop
+					}
+					ids = append(ids, post.FullID)
+				case err, ok := <-errs:
+					if !ok {
+						break loop
+					}
+					require.NoError(t, err)
+				}
+			}
+
+			require.Equal(t, []string{"t3_post3", "t3_post2"}, ids)
+		})
+	}
+}
+
+func TestStreamService_Posts_FatalErrors(t *testing.T) {
+	tests := map[string]struct {
+		status int
+		fatal  bool
+	}{
+		"unauthorized":      {status: http.StatusUnauthorized, fatal: true},
+		"forbidden":         {status: http.StatusForbidden, fatal: true},
+		"too many requests": {status: http.StatusTooManyRequests, fatal: false},
+		"server error":      {status: http.StatusInternalServerError, fatal: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, mux := setup(t)
+
+			var counter int
+			mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+				counter++
+				w.WriteHeader(test.status)
+			})
+
+			posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+				WithStreamInterval[*Post](time.Millisecond*10),
+				WithStreamMaxRequests[*Post](3),
+			)
+			defer stop()
+
+			var received []error
+		loop:
+			for {
+				select {
+				case _, ok := <-posts:
+					if !ok {
+						break loop
+					}
+					t.Fatal("unexpected post")
+				case err, ok := <-errs:
+					if !ok {
+						break loop
+					}
+					received = append(received, err)
+				}
+			}
+
+			if test.fatal {
+				// the stream stops on the first one
+				require.Len(t, received, 1)
+				require.Equal(t, 1, counter)
+			} else {
+				require.Len(t, received, 3)
+			}
+			for _, err := range received {
+				require.Equal(t, test.fatal, IsFatalStreamError(err))
+				var errResp *ErrorResponse
+				require.True(t, errors.As(err, &errResp), "unexpected error: %v", err)
+				require.Equal(t, test.status, errResp.Response.StatusCode)
+			}
+		})
+	}
+
+	require.False(t, IsFatalStreamError(errors.New("not a stream error")))
+	require.True(t, IsFatalStreamError(fmt.Errorf("wrapped: %w", &StreamError{Err: ErrSubredditNotFound, Fatal: true})))
+}
+
+// resetRecordingClock is a FakeClock whose tickers report the intervals they're reset to.
+type resetRecordingClock struct {
+	*FakeClock
+	resets chan time.Duration
+}
+
+func (c *resetRecordingClock) NewTicker(d time.Duration) Ticker {
+	return &resetRecordingTicker{fakeTicker: c.FakeClock.NewTicker(d).(*fakeTicker), resets: c.resets}
+}
+
+type resetRecordingTicker struct {
+	*fakeTicker
+	resets chan time.Duration
+}
+
+func (t *resetRecordingTicker) Reset(d time.Duration) {
+	t.fakeTicker.Reset(d)
+	t.resets <- d
+}
+
+func TestStreamController_SetInterval_Retry(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int32
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&counter, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_post1"}}]}}`)
+	})
+
+	clock := &resetRecordingClock{FakeClock: NewFakeClock(time.Now()), resets: make(chan time.Duration, 10)}
+	controller := NewStreamController()
+	posts, _, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamClock[*Post](clock),
+		WithStreamController[*Post](controller),
+		WithStreamInterval[*Post](time.Hour),
+		WithStreamRetry[*Post](1, time.Second),
+	)
+	defer stop()
+
+	require.NoError(t, controller.SetInterval(time.Minute))
+	require.Equal(t, time.Minute, <-clock.resets)
+
+	// the retry waits for its backoff, and then the next fetch is an interval away: the one set via the controller
+	controller.TriggerFetch()
+	for {
+		select {
+		case d := <-clock.resets:
+			require.Equal(t, time.Minute, d)
+			require.Equal(t, "t3_post1", (<-posts).FullID)
+			return
+		case <-time.After(time.Millisecond * 5):
+			clock.Advance(time.Second)
+		}
+	}
+}
+
+func TestStreamController_SetInterval_Adaptive(t *testing.T) {
+	client, _ := setup(t)
+
+	// full pages, which halve the interval every time
+	var counter int
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		defer func() { counter++ }()
+		posts := make([]*Post, itemLimit)
+		for i := range posts {
+			posts[i] = &Post{FullID: fmt.Sprintf("t3_post%d_%d", counter, i)}
+		}
+		return posts, nil
+	}
+
+	clock := &resetRecordingClock{FakeClock: NewFakeClock(time.Now()), resets: make(chan time.Duration, 10)}
+	controller := NewStreamController()
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamClock[*Post](clock),
+		WithStreamController[*Post](controller),
+		WithStreamInterval[*Post](time.Second*40),
+		WithGetFunc(getPosts),
+		WithAdaptiveInterval[*Post](time.Second*5, time.Second*40),
+	)
+	defer stop()
+
+	go func() {
+		for range posts {
+		}
+	}()
+
+	nextReset := func() time.Duration {
+		t.Helper()
+		select {
+		case d := <-clock.resets:
+			return d
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the interval to be reset")
+		}
+		return 0
+	}
+
+	controller.TriggerFetch()
+	require.Equal(t, time.Second*20, nextReset())
+
+	// the interval adapts from the one set via the controller
+	require.NoError(t, controller.SetInterval(time.Second*40))
+	require.Equal(t, time.Second*40, nextReset())
+	controller.TriggerFetch()
+	require.Equal(t, time.Second*20, nextReset())
+}
+
+func TestStreamService_Posts_AdaptiveInterval(t *testing.T) {
+	client, _ := setup(t)
+
+	// full pages, then a half-full one, then nearly empty ones
+	sizes := []int{100, 100, 100, 50, 10, 0, 0, 0}
+	var counter int
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		defer func() { counter++ }()
+		posts := make([]*Post, sizes[counter])
+		for i := range posts {
+			posts[i] = &Post{FullID: fmt.Sprintf("t3_post%d_%d", counter, i)}
+		}
+		return posts, nil
+	}
+
+	clock := &resetRecordingClock{FakeClock: NewFakeClock(time.Now()), resets: make(chan time.Duration, 1)}
+	controller := NewStreamController()
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamClock[*Post](clock),
+		WithStreamController[*Post](controller),
+		WithStreamInterval[*Post](time.Minute),
+		WithStreamMaxRequests[*Post](len(sizes)),
+		WithGetFunc(getPosts),
+		WithAdaptiveInterval[*Post](time.Second*10, time.Second*5),
+		// starts at 40s, brought down from a minute
+		WithAdaptiveInterval[*Post](time.Second*10, time.Second*40),
+	)
+	defer stop()
+
+	go func() {
+		for range posts {
+		}
+	}()
+
+	var intervals []time.Duration
+	for range sizes {
+		controller.TriggerFetch()
+		select {
+		case d := <-clock.resets:
+			intervals = append(intervals, d)
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the interval to be reset")
+		}
+	}
+
+	require.Equal(t, []time.Duration{
+		time.Second * 20,
+		time.Second * 10,
+		time.Second * 10,
+		time.Second * 10,
+		time.Second * 20,
+		time.Second * 40,
+		time.Second * 40,
+		time.Second * 40,
+	}, intervals)
+}
+
+func TestStreamService_Replay(t *testing.T) {
+	client, _ := setup(t)
+
+	recording := `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_post2"}}, {"kind": "t3", "data": {"name": "t3_post1"}}]}}
+{"kind": "Listing", "data": {"children": []}}
+{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_post3"}}, {"kind": "t3", "data": {"name": "t3_post2"}}]}}
+{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_post4"}}, {"kind": "t3", "data": {"name": "t3_post3"}}, {"kind": "t3", "data": {"name": "t3_post1"}}]}}
+`
+
+	tests := map[string]struct {
+		recording string
+		opts      []StreamOpt[*Post]
+		ids       []string
+		err       string
+	}{
+		"all": {
+			recording: recording,
+			ids:       []string{"t3_post2", "t3_post1", "t3_post3", "t3_post4"},
+		},
+		"discard initial": {
+			recording: recording,
+			opts:      []StreamOpt[*Post]{WithStreamDiscardInitial[*Post]()},
+			ids:       []string{"t3_post3", "t3_post4"},
+		},
+		"max requests": {
+			recording: recording,
+			opts:      []StreamOpt[*Post]{WithStreamMaxRequests[*Post](3)},
+			ids:       []string{"t3_post2", "t3_post1", "t3_post3"},
+		},
+		"empty": {
+			recording: "",
+		},
+		"invalid": {
+			recording: recording + `{"kind": `,
+			ids:       []string{"t3_post2", "t3_post1", "t3_post3", "t3_post4"},
+			err:       "replaying response 5: unexpected EOF",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			posts, errs, stop := client.Stream.Replay(context.Background(), strings.NewReader(test.recording), test.opts...)
+			defer stop()
+
+			var ids []string
+			var errMessages []string
+		loop:
+			for {
+				select {
+				case post, ok := <-posts:
+					if !ok {
+						break loop
+					}
+					ids = append(ids, post.FullID)
+				case err, ok := <-errs:
+					if !ok {
+						break loop
+					}
+					errMessages = append(errMessages, err.Error())
+				}
+			}
+
+			require.Equal(t, test.ids, ids)
+			if test.err == "" {
+				require.Empty(t, errMessages)
+			} else {
+				require.Equal(t, []string{test.err}, errMessages)
+			}
+		})
+	}
+}
+
+func TestStreamService_Posts_DropDetection(t *testing.T) {
+	client, _ := setup(t)
+
+	// the posts are numbered by when they were created, one per second
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	pages := [][2]int{{0, 100}, {200, 300}, {250, 350}, {350, 450}}
+	var counter int
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		defer func() { counter++ }()
+		var posts []*Post
+		for i := pages[counter][1] - 1; i >= pages[counter][0]; i-- {
+			posts = append(posts, &Post{
+				FullID:  fmt.Sprintf("t3_post%d", i),
+				Created: &Timestamp{created.Add(time.Duration(i) * time.Second)},
+			})
+		}
+		return posts, nil
+	}
+
+	var missed []int
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](len(pages)),
+		WithGetFunc(getPosts),
+		WithDropDetection[*Post](nil),
+		WithDropDetection[*Post](func(n int) {
+			missed = append(missed, n)
+		}),
+	)
+	defer stop()
+
+	var count int
+loop:
+	for {
+		select {
+		case _, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			count++
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, 350, count)
+	// the first page isn't checked, and the third one reached back to the second one.
+	// The 100 posts between the first two pages were missed, at least as far as the estimate goes
+	require.Equal(t, []int{101, 1}, missed)
+}
+
+func TestEstimateMissed(t *testing.T) {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	posts := []*Post{
+		{FullID: "t3_post3", Created: &Timestamp{created.Add(time.Minute * 3)}},
+		{FullID: "t3_post2", Created: &Timestamp{created.Add(time.Minute * 2)}},
+		{FullID: "t3_post1", Created: &Timestamp{created.Add(time.Minute)}},
+	}
+
+	require.Equal(t, 10, estimateMissed(posts, created.Add(-time.Minute*9)))
+	require.Equal(t, 1, estimateMissed(posts, created.Add(time.Second*30)))
+	require.Equal(t, 1, estimateMissed(posts, time.Time{}))
+	require.Equal(t, 1, estimateMissed([]*Post{{FullID: "t3_post1"}}, created))
+}
+
+func TestStreamService_Posts_Metrics(t *testing.T) {
+	client, _ := setup(t)
+
+	var counter int
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		defer func() { counter++ }()
+		switch counter {
+		case 0:
+			return []*Post{{FullID: "t3_post2"}, {FullID: "t3_post1"}}, nil
+		case 1:
+			return nil, errors.New("something went wrong")
+		default:
+			return []*Post{{FullID: "t3_post4"}, {FullID: "t3_post3"}, {FullID: "t3_post2"}}, nil
+		}
+	}
+
+	// the callback is stuck until every item was received, which must not hold up the stream
+	release := make(chan struct{})
+	var stats []StreamStats
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](3),
+		WithGetFunc(getPosts),
+		WithStreamMetrics[*Post](nil),
+		WithStreamMetrics[*Post](func(s StreamStats) {
+			<-release
+			stats = append(stats, s)
+		}),
+	)
+	defer stop()
+
+	var ids []string
+	for len(ids) < 4 {
+		select {
+		case post := <-posts:
+			ids = append(ids, post.FullID)
+		case err := <-errs:
+			require.EqualError(t, err, "something went wrong")
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the posts")
+		}
+	}
+	close(release)
+
+	_, ok := <-posts
+	require.False(t, ok)
+
+	require.Equal(t, []string{"t3_post2", "t3_post1", "t3_post4", "t3_post3"}, ids)
+	// the callback was busy with the first stats, so some of the ones after it may have been skipped,
+	// but never the last ones
+	require.Equal(t, StreamStats{Requests: 1, ItemsEmitted: 2, LastPageSize: 2}, stats[0])
+	require.Equal(t, StreamStats{Requests: 3, ItemsEmitted: 4, ErrorsSeen: 1, LastPageSize: 3}, stats[len(stats)-1])
+	for i := 1; i < len(stats); i++ {
+		require.Greater(t, stats[i].Requests, stats[i-1].Requests)
+	}
+}
+
+func TestStreamService_Posts_Logger(t *testing.T) {
+	client, _ := setup(t)
+
+	var counter int
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		defer func() { counter++ }()
+		switch counter {
+		case 0:
+			return []*Post{{FullID: "t3_post2"}, {FullID: "t3_post1"}}, nil
+		case 1:
+			return nil, errors.New("something went wrong")
+		default:
+			return []*Post{{FullID: "t3_post3"}, {FullID: "t3_post2"}}, nil
+		}
+	}
+
+	type entry struct {
+		level, msg string
+		kv         []any
+	}
+	var entries []entry
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](3),
+		WithGetFunc(getPosts),
+		WithStreamLogger[*Post](nil),
+		WithStreamLogger[*Post](func(level, msg string, kv ...any) {
+			entries = append(entries, entry{level, msg, kv})
+		}),
+	)
+	defer stop()
+
+loop:
+	for {
+		select {
+		case _, ok := <-posts:
+			if !ok {
+				break loop
+			}
+		case _, ok := <-errs:
+			if !ok {
+				break loop
+			}
+		}
+	}
+
+	require.Equal(t, []entry{
+		{"debug", "fetched page", []any{"subreddit", "testsubreddit", "request", 1, "items", 2, "new", 2}},
+		{"error", "fetch failed", []any{"subreddit", "testsubreddit", "request", 2, "fatal", false, "err", errors.New("something went wrong")}},
+		{"debug", "fetched page", []any{"subreddit", "testsubreddit", "request", 3, "items", 2, "new", 1}},
+		{"info", "stream stopped", []any{"subreddit", "testsubreddit", "reason", "max requests reached", "requests", 3}},
+	}, entries)
+}
+
+func TestStreamService_Modmail(t *testing.T) {
+	client, mux := setup(t)
+
+	responses := []string{
+		`{
+			"conversations": {
+				"1abc2": {"id": "1abc2", "lastUpdated": "2020-01-02T15:04:05.000000+00:00", "objIds": [{"id": "2n0kl", "key": "messages"}]},
+				"1abc1": {"id": "1abc1", "lastUpdated": "2020-01-01T15:04:05.000000+00:00", "objIds": [{"id": "2n0kk", "key": "messages"}]}
+			},
+			"conversationIds": ["1abc2", "1abc1"]
+		}`,
+		// a reply to the first conversation, and a mod action on the second one
+		`{
+			"conversations": {
+				"1abc2": {"id": "1abc2", "lastUpdated": "2020-01-03T15:04:05.000000+00:00", "objIds": [{"id": "2n0kl", "key": "messages"}, {"id": "2n0km", "key": "messages"}]},
+				"1abc1": {"id": "1abc1", "lastUpdated": "2020-01-01T16:04:05.000000+00:00", "objIds": [{"id": "2n0kk", "key": "messages"}, {"id": "abc12", "key": "modActions"}]}
+			},
+			"conversationIds": ["1abc2", "1abc1"]
+		}`,
+	}
+
+	var counter int
+	mux.HandleFunc("/api/mod/conversations", func(w http.ResponseWriter, r *http.Request) {
+		defer func() { counter++ }()
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "testsubreddit", r.URL.Query().Get("entity"))
+		require.Equal(t, "recent", r.URL.Query().Get("sort"))
+		fmt.Fprint(w, responses[counter])
+	})
+
+	conversations, errs, stop := client.Stream.Modmail(context.Background(), "testsubreddit",
+		WithStreamInterval[*ModmailConversation](time.Millisecond*10),
+		WithStreamMaxRequests[*ModmailConversation](len(responses)),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case conversation, ok := <-conversations:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, conversation.GetFullID())
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"1abc2/2n0kl", "1abc1/2n0kk", "1abc2/2n0km"}, ids)
+}
+
+func TestStreamService_Rising(t *testing.T) {
+	client, mux := setup(t)
+
+	responses := []string{
+		`{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post2", "created_utc": 1577836700}},
+					{"kind": "t3", "data": {"name": "t3_post1", "created_utc": 1577836800}}
+				]
+			}
+		}`,
+		// the posts swapped places, and an older one started rising
+		`{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post1", "created_utc": 1577836800}},
+					{"kind": "t3", "data": {"name": "t3_post2", "created_utc": 1577836700}},
+					{"kind": "t3", "data": {"name": "t3_post3", "created_utc": 1577836600}}
+				]
+			}
+		}`,
+	}
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/rising", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "100", r.Form.Get("limit"))
+		require.Empty(t, r.Form.Get("before"))
+		defer func() { counter++ }()
+		fmt.Fprint(w, responses[counter])
+	})
+
+	posts, errs, stop := client.Stream.Rising(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](len(responses)),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post2", "t3_post1", "t3_post3"}, ids)
+}
+
+func TestStreamService_Posts_DrainOnStop(t *testing.T) {
+	client, _ := setup(t)
+
+	var requests int32
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		atomic.AddInt32(&requests, 1)
+		return []*Post{{FullID: "t3_post3"}, {FullID: "t3_post2"}, {FullID: "t3_post1"}}, nil
+	}
+
+	posts, _, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithGetFunc(getPosts),
+		WithStreamBuffer[*Post](1),
+		WithDrainOnStop[*Post](),
+	)
+
+	post := <-posts
+	require.Equal(t, "t3_post3", post.FullID)
+	stop()
+	stop()
+
+	ids := []string{post.FullID}
+	for post := range posts {
+		ids = append(ids, post.FullID)
+	}
+	require.Equal(t, []string{"t3_post3", "t3_post2", "t3_post1"}, ids)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestStreamService_Posts_DrainOnStopCanceled(t *testing.T) {
+	client, _ := setup(t)
+
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		return []*Post{{FullID: "t3_post3"}, {FullID: "t3_post2"}, {FullID: "t3_post1"}}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	posts, errs, stop := client.Stream.Posts(ctx, "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithGetFunc(getPosts),
+		WithDrainOnStop[*Post](),
+	)
+
+	<-posts
+	stop()
+	// nobody is receiving the remaining posts anymore, so only canceling lets the stream finish
+	cancel()
+
+	select {
+	case _, ok := <-errs:
+		for ok {
+			_, ok = <-errs
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the stream to finish")
+	}
+}
+
+func TestStreamService_Posts_ExistingHighWaterMark(t *testing.T) {
+	client, _ := setup(t)
+
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		return []*Post{{FullID: "t3_post3"}, {FullID: "t3_post2"}, {FullID: "t3_post1"}}, nil
+	}
+
+	// persisted by a previous run of the stream
+	mark, err := NewHighWaterMarkFromJSON([]byte(`{"marks": ["t3_post1", "t3_post2"], "cap": 10}`))
+	require.NoError(t, err)
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](2),
+		WithGetFunc(getPosts),
+		WithExistingHighWaterMark[*Post](nil),
+		WithExistingHighWaterMark[*Post](mark),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post3"}, ids)
+	require.True(t, mark.Contains("t3_post3"))
+
+	// persisted again for the next run
+	data, err := json.Marshal(mark)
+	require.NoError(t, err)
+	restored, err := NewHighWaterMarkFromJSON(data)
+	require.NoError(t, err)
+	require.Equal(t, mark.Len(), restored.Len())
+	require.True(t, restored.Contains("t3_post3"))
+}
+
+func TestStreamService_Modnotes(t *testing.T) {
+	client, mux := setup(t)
+
+	responses := []string{
+		`{"mod_notes": [
+			{"id": "ModNote_2", "created_at": 1706602167, "type": "NOTE"},
+			{"id": "ModNote_1", "created_at": 1706602158, "type": "REMOVAL"}
+		]}`,
+		`{"mod_notes": [
+			{"id": "ModNote_3", "created_at": 1706602200, "type": "BAN"},
+			{"id": "ModNote_2", "created_at": 1706602167, "type": "NOTE"},
+			{"id": "ModNote_1", "created_at": 1706602158, "type": "REMOVAL"}
+		]}`,
+	}
+
+	var counter int
+	mux.HandleFunc("/api/mod/notes", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "testsubreddit", r.URL.Query().Get("subreddit"))
+		require.Equal(t, "testuser", r.URL.Query().Get("user"))
+		require.Equal(t, "100", r.URL.Query().Get("limit"))
+		defer func() { counter++ }()
+		fmt.Fprint(w, responses[counter])
+	})
+
+	notes, errs, stop := client.Stream.Modnotes(context.Background(), "testsubreddit", "testuser",
+		WithStreamInterval[*Modnote](time.Millisecond*10),
+		WithStreamMaxRequests[*Modnote](len(responses)),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case note, ok := <-notes:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, note.Id)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"ModNote_2", "ModNote_1", "ModNote_3"}, ids)
+}
+
+func TestStreamService_Inbox(t *testing.T) {
+	client, mux := setup(t)
+
+	message := func(kind, id string, created int, isNew bool) string {
+		return fmt.Sprintf(`{"kind": %q, "data": {"id": %q, "name": "%s_%s", "was_comment": %t, "created_utc": %d, "new": %t}}`,
+			kind, id, kind, id, kind == kindComment, created, isNew)
+	}
+	listing := func(children ...string) string {
+		return fmt.Sprintf(`{"kind": "Listing", "data": {"children": [%s]}}`, strings.Join(children, ","))
+	}
+
+	responses := []string{
+		listing(message("t1", "c1", 1597710253, false), message("t4", "m1", 1597709813, false)),
+		// already read messages stay in the inbox, along with the new ones
+		listing(message("t1", "c2", 1597710353, true), message("t1", "c1", 1597710253, false), message("t4", "m2", 1597710453, false), message("t4", "m1", 1597709813, false)),
+	}
+
+	var counter int
+	mux.HandleFunc("/message/inbox", func(w http.ResponseWriter, r *http.Request) {
+		defer func() { counter++ }()
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, responses[counter])
+	})
+
+	comments, dms, errs, stop := client.Stream.Inbox(context.Background(),
+		WithStreamInterval[*Message](time.Millisecond*10),
+		WithStreamMaxRequests[*Message](len(responses)),
+	)
+	defer stop()
+
+	var commentIDs, dmIDs []string
+	for comments != nil || dms != nil || errs != nil {
+		select {
+		case comment, ok := <-comments:
+			if !ok {
+				comments = nil
+				continue
+			}
+			require.True(t, comment.IsComment)
+			commentIDs = append(commentIDs, comment.FullID)
+		case dm, ok := <-dms:
+			if !ok {
+				dms = nil
+				continue
+			}
+			require.False(t, dm.IsComment)
+			dmIDs = append(dmIDs, dm.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t1_c1", "t1_c2"}, commentIDs)
+	require.Equal(t, []string{"t4_m1", "t4_m2"}, dmIDs)
+}
+
+func TestStreamService_InboxUnread_AutoMarkRead(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/message/unread", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [
+			{"kind": "t1", "data": {"id": "c1", "name": "t1_c1", "was_comment": true, "created_utc": 1597710253, "new": true}},
+			{"kind": "t4", "data": {"id": "m1", "name": "t4_m1", "was_comment": false, "created_utc": 1597709813, "new": true}}
+		]}}`)
+	})
+
+	var read []string
+	mux.HandleFunc("/api/read_message", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, r.ParseForm())
+		id := r.Form.Get("id")
+		read = append(read, id)
+		if id == "t4_m1" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	comments, dms, errs, stop := client.Stream.InboxUnread(context.Background(),
+		WithStreamInterval[*Message](time.Millisecond*10),
+		WithStreamMaxRequests[*Message](1),
+		WithAutoMarkRead(),
+	)
+	defer stop()
+
+	var received []string
+	var streamErrs []error
+	for comments != nil || dms != nil || errs != nil {
+		select {
+		case comment, ok := <-comments:
+			if !ok {
+				comments = nil
+				continue
+			}
+			received = append(received, comment.FullID)
+		case dm, ok := <-dms:
+			if !ok {
+				dms = nil
+				continue
+			}
+			received = append(received, dm.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			streamErrs = append(streamErrs, err)
+		}
+	}
+
+	require.Equal(t, []string{"t1_c1", "t4_m1"}, received)
+	require.Equal(t, []string{"t1_c1", "t4_m1"}, read)
+	require.Len(t, streamErrs, 1)
+	require.False(t, IsFatalStreamError(streamErrs[0]))
+	require.Contains(t, streamErrs[0].Error(), "marking t4_m1 as read")
+}
+
+func TestStreamService_Jitter(t *testing.T) {
+	client, mux := setup(t)
+
+	empty := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+	}
+	mux.HandleFunc("/message/unread", empty)
+	mux.HandleFunc("/r/testsubreddit/about/reports", empty)
+	mux.HandleFunc("/r/testsubreddit/new", empty)
+
+	const interval, jitter = time.Minute, time.Second * 10
+
+	streams := map[string]func(ctx context.Context, clock Clock, controller *StreamController) <-chan error{
+		"posts": func(ctx context.Context, clock Clock, controller *StreamController) <-chan error {
+			_, errs, _ := client.Stream.Posts(ctx, "testsubreddit",
+				WithStreamClock[*Post](clock),
+				WithStreamController[*Post](controller),
+				WithStreamInterval[*Post](interval),
+				WithStreamJitter[*Post](jitter),
+			)
+			return errs
+		},
+		"inbox unread": func(ctx context.Context, clock Clock, controller *StreamController) <-chan error {
+			_, _, errs, _ := client.Stream.InboxUnread(ctx,
+				WithStreamClock[*Message](clock),
+				WithStreamController[*Message](controller),
+				WithStreamInterval[*Message](interval),
+				WithStreamJitter[*Message](jitter),
+			)
+			return errs
+		},
+		"reported": func(ctx context.Context, clock Clock, controller *StreamController) <-chan error {
+			_, _, errs, _ := client.Stream.Reported(ctx, "testsubreddit",
+				WithStreamClock[Streamable](clock),
+				WithStreamController[Streamable](controller),
+				WithStreamInterval[Streamable](interval),
+				WithStreamJitter[Streamable](jitter),
+			)
+			return errs
+		},
+	}
+
+	for name, start := range streams {
+		t.Run(name, func(t *testing.T) {
+			clock := &resetRecordingClock{FakeClock: NewFakeClock(time.Now()), resets: make(chan time.Duration, 1)}
+			controller := NewStreamController()
+			ctx, cancel := context.WithCancel(context.Background())
+			errs := start(ctx, clock, controller)
+
+			var intervals []time.Duration
+			for i := 0; i < 5; i++ {
+				controller.TriggerFetch()
+				select {
+				case d := <-clock.resets:
+					intervals = append(intervals, d)
+				case <-time.After(time.Second):
+					t.Fatal("timed out waiting for the ticker to be reset")
+				}
+			}
+
+			// canceling lets the stream close its channels once it's done with its last fetch
+			cancel()
+			for range errs {
+			}
+
+			for _, d := range intervals {
+				require.True(t, d >= interval && d <= interval+jitter, "interval %s out of range", d)
+			}
+			require.NotEqual(t, intervals[0], intervals[1])
+		})
+	}
+}
+
+func TestCustomStream(t *testing.T) {
+	client, mux := setup(t)
+
+	responses := []string{
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post2", "created_utc": 1597710253}},
+			{"kind": "t3", "data": {"name": "t3_post1", "created_utc": 1597710153}}
+		]}}`,
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post3", "created_utc": 1597710353}}
+		]}}`,
+	}
+
+	var befores []string
+	var counter int
+	mux.HandleFunc("/user/user1/saved", func(w http.ResponseWriter, r *http.Request) {
+		defer func() { counter++ }()
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "100", r.URL.Query().Get("limit"))
+		befores = append(befores, r.URL.Query().Get("before"))
+		fmt.Fprint(w, responses[counter])
+	})
+
+	// the user's saved posts, which have no dedicated stream
+	saved := func(ctx context.Context, _ string, before string) ([]*Post, error) {
+		posts, _, _, err := client.User.Saved(ctx, &ListUserOverviewOptions{ListOptions: ListOptions{Limit: 100, Before: before}})
+		return posts, err
+	}
+	posts, errs, stop := CustomStream(context.Background(), "", saved,
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](len(responses)),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post2", "t3_post1", "t3_post3"}, ids)
+	// the stream does the deduplication itself, so it always asks for the newest page
+	require.Equal(t, []string{"", ""}, befores)
+}
+
+func TestStreamService_Comments_StartFromFullID(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/r/testsubreddit/comments", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t1", "data": {"name": "t1_comment3"}},
+					{"kind": "t1", "data": {"name": "t1_comment2"}},
+					{"kind": "t1", "data": {"name": "t1_comment1"}}
+				]
+			}
+		}`)
+	})
+
+	comments, errs, stop := client.Stream.Comments(context.Background(), "testsubreddit",
+		WithStreamInterval[*Comment](time.Millisecond*10),
+		WithStreamMaxRequests[*Comment](2),
+		WithStartFromFullID[*Comment]("t1_comment2"),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case comment, ok := <-comments:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, comment.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	// comment2 was already streamed before, and so was comment1 before it
+	require.Equal(t, []string{"t1_comment3"}, ids)
+}
+
+func TestStreamService_InboxUnread_DiscardInitial(t *testing.T) {
+	client, mux := setup(t)
+
+	message := func(kind, id string) string {
+		return fmt.Sprintf(`{"kind": %q, "data": {"id": %q, "name": "%s_%s", "was_comment": %t}}`, kind, id, kind, id, kind == kindComment)
+	}
+	responses := []string{
+		fmt.Sprintf(`{"kind": "Listing", "data": {"children": [%s, %s]}}`, message("t1", "c1"), message("t4", "m1")),
+		fmt.Sprintf(`{"kind": "Listing", "data": {"children": [%s, %s, %s]}}`, message("t1", "c2"), message("t1", "c1"), message("t4", "m1")),
+	}
+	var counter int
+	mux.HandleFunc("/message/unread", func(w http.ResponseWriter, r *http.Request) {
+		defer func() { counter++ }()
+		fmt.Fprint(w, responses[counter])
+	})
+
+	comments, dms, errs, stop := client.Stream.InboxUnread(context.Background(),
+		WithStreamInterval[*Message](time.Millisecond*10),
+		WithStreamMaxRequests[*Message](len(responses)),
+		WithStreamDiscardInitial[*Message](),
+	)
+	defer stop()
+
+	var ids []string
+	for comments != nil || dms != nil || errs != nil {
+		select {
+		case comment, ok := <-comments:
+			if !ok {
+				comments = nil
+				continue
+			}
+			ids = append(ids, comment.FullID)
+		case dm, ok := <-dms:
+			if !ok {
+				dms = nil
+				continue
+			}
+			ids = append(ids, dm.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	// the whole first page is discarded, DMs included
+	require.Equal(t, []string{"t1_c2"}, ids)
+}
+
+func TestStreamService_Reported_StartFromFullID(t *testing.T) {
+	client, mux := setup(t)
+
+	responses := []string{
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post3", "num_reports": 1}},
+			{"kind": "t3", "data": {"name": "t3_post2", "num_reports": 1}},
+			{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 1}}
+		]}}`,
+		// post1 was handled before the stream was restarted, and gets reported again since
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post3", "num_reports": 1}},
+			{"kind": "t3", "data": {"name": "t3_post2", "num_reports": 1}},
+			{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 2}}
+		]}}`,
+	}
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		defer func() { counter++ }()
+		fmt.Fprint(w, responses[counter])
+	})
+
+	controller := NewStreamController()
+	posts, _, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit",
+		WithStreamInterval[Streamable](time.Millisecond*10),
+		WithStreamMaxRequests[Streamable](len(responses)),
+		WithStartFromFullID[Streamable]("t3_post2"),
+		WithStreamController[Streamable](controller),
+	)
+	defer stop()
+
+	var ids []string
+	for posts != nil || errs != nil {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				posts = nil
+				continue
+			}
+			ids = append(ids, fmt.Sprintf("%s/%d", post.FullID, post.NumReports))
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post3/1", "t3_post1/2"}, ids)
+	require.Equal(t, "t3_post1", controller.Cursor())
+}
+
+func TestStreamService_Reported_MaxRequestsDiscardInitial(t *testing.T) {
+	client, mux := setup(t)
+
+	responses := []string{
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t1", "data": {"name": "t1_comment1", "num_reports": 1}},
+			{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 1}},
+			{"kind": "t1", "data": {"name": "t1_comment2", "num_reports": 1}},
+			{"kind": "t3", "data": {"name": "t3_post2", "num_reports": 1}}
+		]}}`,
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post3", "num_reports": 1}},
+			{"kind": "t1", "data": {"name": "t1_comment3", "num_reports": 1}},
+			{"kind": "t1", "data": {"name": "t1_comment1", "num_reports": 1}},
+			{"kind": "t3", "data": {"name": "t3_post1", "num_reports": 1}},
+			{"kind": "t1", "data": {"name": "t1_comment2", "num_reports": 1}},
+			{"kind": "t3", "data": {"name": "t3_post2", "num_reports": 1}}
+		]}}`,
+		`{"kind": "Listing", "data": {"children": [
+			{"kind": "t3", "data": {"name": "t3_post4", "num_reports": 1}}
+		]}}`,
+	}
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/about/reports", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+		fmt.Fprint(w, responses[counter])
+	})
+
+	collect := func(t *testing.T, opts ...StreamOpt[Streamable]) []string {
+		opts = append(opts, WithStreamInterval[Streamable](time.Millisecond*10))
+		posts, comments, errs, stop := client.Stream.Reported(context.Background(), "testsubreddit", opts...)
+		defer stop()
+
+		var ids []string
+		for posts != nil || comments != nil || errs != nil {
+			select {
+			case post, ok := <-posts:
+				if !ok {
+					posts = nil
+					continue
+				}
+				ids = append(ids, post.FullID)
+			case comment, ok := <-comments:
+				if !ok {
+					comments = nil
+					continue
+				}
+				ids = append(ids, comment.FullID)
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				require.NoError(t, err)
+			}
+		}
+		return ids
+	}
+
+	// neither the posts nor the comments of the first page are streamed, and no request is made past the max
+	for _, tc := range []struct {
+		maxRequests int
+		expected    []string
+	}{
+		{maxRequests: 1, expected: nil},
+		{maxRequests: 2, expected: []string{"t3_post3", "t1_comment3"}},
+	} {
+		t.Run(fmt.Sprintf("%d requests", tc.maxRequests), func(t *testing.T) {
+			counter = 0
+			ids := collect(t, WithStreamMaxRequests[Streamable](tc.maxRequests), WithStreamDiscardInitial[Streamable]())
+			require.ElementsMatch(t, tc.expected, ids)
+			require.Equal(t, tc.maxRequests, counter)
+		})
+	}
+
+	// a single discarded request primes a mark, which a later stream picks up from
+	t.Run("priming a mark", func(t *testing.T) {
+		counter = 0
+		mark := NewHighWaterMark(defaultHighWaterMarkCapacity)
+		require.Empty(t, collect(t,
+			WithStreamMaxRequests[Streamable](1),
+			WithStreamDiscardInitial[Streamable](),
+			WithExistingHighWaterMark[Streamable](mark),
+		))
+		require.ElementsMatch(t, []string{"t3_post3", "t1_comment3"}, collect(t,
+			WithStreamMaxRequests[Streamable](1),
+			WithExistingHighWaterMark[Streamable](mark),
+		))
+	})
+}
+
+func TestStreamService_Posts_ErrorHandler(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		defer func() { counter++ }()
+		if counter%2 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"name": "t3_post1"}}]}}`)
+	})
+
+	t.Run("channel", func(t *testing.T) {
+		counter = 0
+		posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+			WithStreamInterval[*Post](time.Millisecond*10),
+			WithStreamMaxRequests[*Post](2),
+		)
+		defer stop()
+
+		err := <-errs
+		require.IsType(t, &StreamError{}, err)
+		require.Equal(t, "t3_post1", (<-posts).FullID)
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		counter = 0
+		var handled []error
+		posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+			WithStreamInterval[*Post](time.Millisecond*10),
+			WithStreamMaxRequests[*Post](2),
+			WithStreamErrorHandler[*Post](func(err error) {
+				handled = append(handled, err)
+			}),
+		)
+		defer stop()
+
+		// only the posts are read, without the stream getting stuck on its error
+		var ids []string
+		for post := range posts {
+			ids = append(ids, post.FullID)
+		}
+		require.Equal(t, []string{"t3_post1"}, ids)
+
+		_, ok := <-errs
+		require.False(t, ok)
+		require.Len(t, handled, 1)
+		require.IsType(t, &StreamError{}, handled[0])
+	})
+}
+
+func TestStreamService_InboxUnread_ErrorHandler(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/message/unread", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind": "Listing", "data": {"children": [{"kind": "t4", "data": {"id": "m1", "name": "t4_m1"}}]}}`)
+	})
+	mux.HandleFunc("/api/read_message", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	var handled []error
+	comments, dms, errs, stop := client.Stream.InboxUnread(context.Background(),
+		WithStreamInterval[*Message](time.Millisecond*10),
+		WithStreamMaxRequests[*Message](1),
+		WithAutoMarkRead(),
+		WithStreamErrorHandler[*Message](func(err error) {
+			handled = append(handled, err)
+		}),
+	)
+	defer stop()
+
+	var ids []string
+	for dm := range dms {
+		ids = append(ids, dm.FullID)
+	}
+	_, ok := <-comments
+	require.False(t, ok)
+	_, ok = <-errs
+	require.False(t, ok)
+
+	require.Equal(t, []string{"t4_m1"}, ids)
+	require.Len(t, handled, 1)
+	require.Contains(t, handled[0].Error(), "marking t4_m1 as read")
+}
+
+func TestStreamService_Posts_ErrorChannelPolicy(t *testing.T) {
+	tests := map[ErrorPolicy][]string{
+		ErrorPolicyDropNewest: {"fetch 1", "fetch 2"},
+		ErrorPolicyDropOldest: {"fetch 4", "fetch 5"},
+	}
+
+	for policy, expected := range tests {
+		t.Run(policy.String(), func(t *testing.T) {
+			client, _ := setup(t)
+
+			var counter int
+			getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+				counter++
+				return nil, fmt.Errorf("fetch %d", counter)
+			}
+
+			posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+				WithStreamInterval[*Post](time.Millisecond*10),
+				WithStreamMaxRequests[*Post](5),
+				WithGetFunc(getPosts),
+				WithStreamBuffer[*Post](2),
+				WithErrorChannelPolicy[*Post](policy),
+			)
+			defer stop()
+
+			// the errors aren't read until the stream is done, which it gets to without waiting on them
+			for range posts {
+			}
+
+			var received []string
+			for err := range errs {
+				received = append(received, err.Error())
+			}
+			require.Equal(t, expected, received)
+		})
+	}
+
+	require.Equal(t, "block", ErrorPolicyBlock.String())
+	require.Equal(t, "ErrorPolicy(7)", ErrorPolicy(7).String())
+}
+
+func TestStreamService_Posts_FlairFilter(t *testing.T) {
+	client, _ := setup(t)
+
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		return []*Post{
+			{FullID: "t3_post1", LinkFlairText: "Announcement"},
+			{FullID: "t3_post2", LinkFlairText: "Question"},
+			{FullID: "t3_post3"},
+			{FullID: "t3_post4", LinkFlairText: "announcement"},
+		}, nil
+	}
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](1),
+		WithGetFunc(getPosts),
+		WithPostFlairFilter[*Post](""),
+		WithPostFlairFilter[*Post]("Announcement"),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post1", "t3_post4"}, ids)
+}
+
+func TestStreamService_Posts_Filter(t *testing.T) {
+	client, _ := setup(t)
+
+	getPosts := func(ctx context.Context, subreddit string, before string) ([]*Post, error) {
+		return []*Post{
+			{FullID: "t3_post1", Author: "alice", NSFW: true},
+			{FullID: "t3_post2", Author: "bob"},
+			{FullID: "t3_post3", Author: "alice"},
+		}, nil
+	}
+
+	var checked []string
+	byAlice := func(post *Post) bool {
+		checked = append(checked, post.FullID)
+		return post.Author == "alice"
+	}
+	sfw := func(post *Post) bool {
+		return !post.NSFW
+	}
+
+	posts, errs, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](3),
+		WithGetFunc(getPosts),
+		WithStreamFilter[*Post](nil),
+		WithStreamFilter(byAlice),
+		WithStreamFilter(sfw),
+	)
+	defer stop()
+
+	var ids []string
+loop:
+	for {
+		select {
+		case post, ok := <-posts:
+			if !ok {
+				break loop
+			}
+			ids = append(ids, post.FullID)
+		case err, ok := <-errs:
+			if !ok {
+				break loop
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	require.Equal(t, []string{"t3_post3"}, ids)
+	// the skipped posts are recorded as seen, so they're only checked on the first fetch
+	require.Equal(t, []string{"t3_post1", "t3_post2", "t3_post3"}, checked)
+}
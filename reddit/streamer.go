@@ -2,19 +2,81 @@ package reddit
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
 	"time"
 )
 
 const defaultStreamInterval = time.Second * 5
 
+// The number of full IDs a stream remembers by default, to avoid emitting the same item twice.
+// It's 10 times the amount of items a single fetch can return.
+const defaultHighWaterMarkCapacity = 1000
+
+// The longest a stream waits before retrying a failed fetch.
+const maxRetryBackoff = time.Minute
+
 type streamConfig[T Streamable] struct {
 	Interval       time.Duration
 	DiscardInitial bool
 	MaxRequests    int
+	// If set, the interval adapts to how full the fetched pages are, within these bounds.
+	AdaptiveMin, AdaptiveMax time.Duration
 
 	UseDumbLogic  bool
 	HighWaterMark HighWaterMark
 	GetFunc       func(context.Context, string, string) ([]T, error)
+	// If set, used instead of the high water mark to find out which items were already seen.
+	DedupStore DedupStore
+
+	FirstPageLimit int
+	// Set by the streams whose listing can be paged through with the "after" parameter.
+	getAfter func(ctx context.Context, subreddit string, after string, limit int) ([]T, error)
+	// Set by doStream2, whose own goroutine outlives the one of the stream it wraps,
+	// so that it's the one telling the controller that the stream is done.
+	wrapped bool
+	// Set by the streams that emit an item again when its state changes, instead of only the first time it's seen.
+	// It's called for every fetched item, and tells whether the item is worth emitting.
+	changed func(item T) bool
+
+	RequireAuthor  bool
+	MinScore       *int
+	MinUpvoteRatio float64
+	Domains        []string
+	Filters        []func(T) bool
+	ContentHash    func(T) string
+	contentHashes  DedupStore
+	CircuitBreaker *circuitBreaker
+	Compaction     time.Duration
+	MinAge         time.Duration
+	Prefetch       bool
+	DrainOnStop    bool
+	Buffer         int
+	MaxEmpty       int
+	MaxRetries     int
+	RetryBase      time.Duration
+	// Called when a fetched page might not have reached back to the items that were already seen.
+	DropDetection func(missed int)
+	Metrics       func(StreamStats)
+
+	Clock      Clock
+	Controller *StreamController
+	Logger     func(level, msg string, kv ...any)
+	// If set, errors are handed to it instead of being sent into the errors channel.
+	ErrorHandler func(error)
+	ErrorPolicy  ErrorPolicy
+
+	// Source of all randomized behavior, such as jitter and sampling.
+	Rand       *rand.Rand
+	Jitter     time.Duration
+	SampleRate float64
+
+	// Only used by the mod actions stream.
+	Moderator string
+	// Only used by the top posts stream.
+	Time string
+	// Only used by the inbox streams.
+	MarkRead bool
 }
 
 func NewStreamConfig[T Streamable]() *streamConfig[T] {
@@ -23,8 +85,215 @@ func NewStreamConfig[T Streamable]() *streamConfig[T] {
 		DiscardInitial: false,
 		MaxRequests:    0,
 		UseDumbLogic:   false,
-		HighWaterMark:  NewHighWaterMark(10),
+		HighWaterMark:  NewHighWaterMark(defaultHighWaterMarkCapacity),
+		Clock:          realClock{},
+		Rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// dedupStore returns the store keeping track of the items the stream has seen.
+func (c *streamConfig[T]) dedupStore() DedupStore {
+	if c.DedupStore != nil {
+		return c.DedupStore
+	}
+	return c.HighWaterMark
+}
+
+// nextInterval returns the interval to wait until the next fetch, including jitter.
+func (c *streamConfig[T]) nextInterval() time.Duration {
+	d := c.baseInterval()
+	if c.Jitter <= 0 {
+		return d
+	}
+	return d + time.Duration(c.Rand.Int63n(int64(c.Jitter)))
+}
+
+// baseInterval returns the interval of the stream without jitter. An interval set via the stream's controller
+// takes over from the current one, e.g. the configured one, which an adaptive interval then adapts from.
+func (c *streamConfig[T]) baseInterval() time.Duration {
+	if c.Controller != nil {
+		if d, ok := c.Controller.takeInterval(); ok {
+			c.Interval = d
+		}
+	}
+	return c.Interval
+}
+
+// rejitter draws a new jitter for the next fetch, if the stream has any, by resetting the ticker after it ticked.
+func (c *streamConfig[T]) rejitter(ticker Ticker) {
+	if c.Jitter <= 0 {
+		return
+	}
+	ticker.Reset(c.nextInterval())
+}
+
+// adaptInterval returns the interval to wait after a fetch that returned n items, with an adaptive interval:
+// half the current one if the page was full, since items were likely missed, and twice as long
+// if it was nearly empty, within the bounds of the adaptive interval.
+func (c *streamConfig[T]) adaptInterval(n int) time.Duration {
+	d := c.baseInterval()
+	switch {
+	case n >= itemLimit:
+		d /= 2
+	case n <= itemLimit/10:
+		d *= 2
+	}
+	return c.clampInterval(d)
+}
+
+// clampInterval returns d within the bounds of the adaptive interval.
+func (c *streamConfig[T]) clampInterval(d time.Duration) time.Duration {
+	if d < c.AdaptiveMin {
+		return c.AdaptiveMin
+	}
+	if d > c.AdaptiveMax {
+		return c.AdaptiveMax
+	}
+	return d
+}
+
+// retryBackoff returns how long to wait before the given retry of a failed fetch, starting from 0.
+// It doubles with every retry, up to maxRetryBackoff.
+func (c *streamConfig[T]) retryBackoff(retry int) time.Duration {
+	backoff := c.RetryBase
+	for i := 0; i < retry && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// sampled reports whether an item should be emitted, according to the sample rate.
+func (c *streamConfig[T]) sampled() bool {
+	return c.SampleRate == 0 || c.Rand.Float64() < c.SampleRate
+}
+
+// log passes the message on to the logger, if there's one.
+func (c *streamConfig[T]) log(level, msg string, kv ...any) {
+	if c.Logger != nil {
+		c.Logger(level, msg, kv...)
+	}
+}
+
+// kept reports whether the item passes every filter set with WithStreamFilter.
+func (c *streamConfig[T]) kept(item T) bool {
+	for _, keep := range c.Filters {
+		if !keep(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// errorsBuffer returns the size of the buffer of the errors channel.
+// Dropping errors when the channel is full only makes sense if it can hold some.
+func (c *streamConfig[T]) errorsBuffer() int {
+	if c.ErrorPolicy != ErrorPolicyBlock && c.Buffer < 1 {
+		return 1
+	}
+	return c.Buffer
+}
+
+// cancelledSendGrace is how long the blocked sends into the errors channel wait for the consumer once the context
+// of the stream is done, after which the errors are dropped, since the consumer could have stopped reading.
+// It's real time, even with WithStreamClock: a fake clock nobody advances anymore would keep the stream around.
+const cancelledSendGrace = time.Second
+
+// errSender returns a function that hands the errors to the error handler if there's one, and otherwise
+// sends them into errsCh according to the error policy. The errors are sent from a single goroutine.
+// A blocked send gives up like with sendWithinGrace.
+func (c *streamConfig[T]) errSender(ctx context.Context, errsCh chan error, stopped <-chan struct{}) func(error) {
+	var deadline time.Time
+	return func(err error) {
+		if c.ErrorHandler != nil {
+			c.ErrorHandler(err)
+			return
+		}
+
+		switch c.ErrorPolicy {
+		case ErrorPolicyDropNewest:
+			select {
+			case errsCh <- err:
+			default:
+				c.log("warn", "errors channel is full, dropping error", "err", err)
+			}
+		case ErrorPolicyDropOldest:
+			// the consumer could take the room that was just made, so it's tried again until the error is in
+			for {
+				select {
+				case errsCh <- err:
+					return
+				default:
+				}
+				select {
+				case dropped := <-errsCh:
+					c.log("warn", "errors channel is full, dropping error", "err", dropped)
+				default:
+				}
+			}
+		default:
+			if !sendWithinGrace(ctx, errsCh, stopped, err, &deadline) {
+				c.log("warn", "context is done and nothing receives from the errors channel, dropping error", "err", err)
+			}
+		}
+	}
+}
+
+// sendWithinGrace sends err into errsCh, giving up once stopped is closed, or once ctx is done and nothing
+// received anything until the deadline, which is set to the grace period from the first send blocked after
+// ctx is done. It only reports false if it gave up because of the deadline.
+func sendWithinGrace(ctx context.Context, errsCh chan<- error, stopped <-chan struct{}, err error, deadline *time.Time) bool {
+	select {
+	case errsCh <- err:
+		return true
+	case <-stopped:
+		return true
+	case <-ctx.Done():
+	}
+
+	if deadline.IsZero() {
+		*deadline = time.Now().Add(cancelledSendGrace)
+	}
+	wait := time.Until(*deadline)
+	if wait <= 0 {
+		return false
+	}
+	grace := time.NewTimer(wait)
+	defer grace.Stop()
+	select {
+	case errsCh <- err:
+		return true
+	case <-stopped:
+		return true
+	case <-grace.C:
+		return false
+	}
+}
+
+// validate checks that the options applied to the config don't conflict with each other.
+// Options that would be ignored are an error, while the ones that are merely redundant are only logged.
+func (c *streamConfig[T]) validate() error {
+	if c.UseDumbLogic && c.MinAge > 0 {
+		return fmt.Errorf("%w: items held back by the min age would never be fetched again with the dumb logic", ErrInvalidStreamConfig)
+	}
+	if c.FirstPageLimit > 0 && c.UseDumbLogic {
+		return fmt.Errorf("%w: the first page limit is ignored with the dumb logic", ErrInvalidStreamConfig)
 	}
+	if c.FirstPageLimit > 0 && c.GetFunc != nil {
+		return fmt.Errorf("%w: the first page limit is ignored with a custom get func", ErrInvalidStreamConfig)
+	}
+	if c.ErrorHandler != nil && c.ErrorPolicy != ErrorPolicyBlock {
+		return fmt.Errorf("%w: the error channel policy is ignored when errors go to an error handler", ErrInvalidStreamConfig)
+	}
+	if c.MaxEmpty > 0 && c.MaxRequests > 0 && c.MaxEmpty > c.MaxRequests {
+		c.log("warn", "the stream stops at its max requests before reaching its max consecutive empty fetches", "maxRequests", c.MaxRequests, "maxEmpty", c.MaxEmpty)
+	}
+	if c.CircuitBreaker != nil && c.MaxRequests > 0 && c.CircuitBreaker.threshold > c.MaxRequests {
+		c.log("warn", "the stream stops at its max requests before its circuit breaker can trip", "maxRequests", c.MaxRequests, "threshold", c.CircuitBreaker.threshold)
+	}
+	return nil
 }
 
 // StreamOpt is a configuration option to configure a stream.
@@ -57,27 +326,419 @@ func WithStreamMaxRequests[T Streamable](v int) StreamOpt[T] {
 	}
 }
 
-// WithStartFromFullID gives a basic HighWaterMark struct
+// WithStartFromFullID resumes the stream from the item with the given full ID, e.g. one persisted from
+// (*StreamController).Cursor: on the first fetch, only the items that come before it in the listing are emitted.
 func WithStartFromFullID[T Streamable](v string) StreamOpt[T] {
 	return func(c *streamConfig[T]) {
-		c.HighWaterMark = NewHighWaterMark(10, v)
+		c.HighWaterMark = NewHighWaterMark(defaultHighWaterMarkCapacity, v)
 	}
 }
 
+// WithHighWaterMark sets the high water mark the stream uses to remember the full IDs of the items it has seen.
+// Once it holds capacity IDs, it forgets about the oldest ones, which could be emitted again if they show up.
+// If items are given, the stream resumes from them, like with WithStartFromFullID.
 func WithHighWaterMark[T Streamable](capacity uint32, items ...string) StreamOpt[T] {
 	return func(c *streamConfig[T]) {
 		c.HighWaterMark = NewHighWaterMark(capacity, items...)
 	}
 }
 
+// WithExistingHighWaterMark makes the stream use the given high water mark, e.g. one restored with
+// NewHighWaterMarkFromJSON, and resume from the items in it. The mark can be persisted with json.Marshal
+// while the stream is running, so that a restarted stream picks up where it left off.
+// If the mark is nil, it is ignored.
+func WithExistingHighWaterMark[T Streamable](mark HighWaterMark) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if mark != nil {
+			c.HighWaterMark = mark
+		}
+	}
+}
+
+// WithStreamDedupStore sets the store the stream uses to remember the full IDs of the items it has seen,
+// instead of its high water mark, e.g. NewLRUDedupStore. If store is nil, it is ignored.
+// A high water mark set with WithStartFromFullID or WithHighWaterMark is still used to resume the stream.
+func WithStreamDedupStore[T Streamable](store DedupStore) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if store != nil {
+			c.DedupStore = store
+		}
+	}
+}
+
+// WithGetFunc replaces the function the stream fetches its items with, e.g. to filter them or to hit a different
+// listing endpoint. It's called with the subreddit of the stream and the "before" parameter to use, if any.
 func WithGetFunc[T Streamable](f func(context.Context, string, string) ([]T, error)) StreamOpt[T] {
 	return func(c *streamConfig[T]) {
 		c.GetFunc = f
 	}
 }
 
+// WithDumbLogic makes the stream use Reddit's own pagination rather than keeping track of the items it has seen:
+// every fetch asks for the items that came before the newest one of the previous fetch, and emits all of them.
+// This is cheaper, and fine for well-behaved subreddits, but if that item gets deleted Reddit returns nothing
+// before it anymore, and the stream gets stuck without emitting anything else.
+// It can't be combined with WithStreamMinAge, since an item that's held back would never be fetched again.
 func WithDumbLogic[T Streamable]() StreamOpt[T] {
 	return func(c *streamConfig[T]) {
 		c.UseDumbLogic = true
 	}
 }
+
+// WithStreamRequireAuthor skips items that have no author, such as posts whose author
+// was shadow-removed. Skipped items are still recorded as seen, so they won't be reconsidered
+// on subsequent fetches.
+func WithStreamRequireAuthor[T Streamable]() StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		c.RequireAuthor = true
+	}
+}
+
+// WithStreamMinScore skips posts and comments whose score is below min. Like with WithStreamRequireAuthor,
+// skipped items are still recorded as seen. Comments whose score is hidden for now are not skipped,
+// since their score isn't known yet. Items that don't have a score, such as mod actions, are never skipped.
+func WithStreamMinScore[T Streamable](min int) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		c.MinScore = &min
+	}
+}
+
+// WithStreamMinUpvoteRatio skips posts whose upvote ratio is below r, which has to be between 0 and 1; otherwise it's ignored.
+// Like with WithStreamRequireAuthor, skipped posts are still recorded as seen. Posts that came back without a ratio,
+// and items that don't have one, such as comments, are never skipped.
+func WithStreamMinUpvoteRatio[T Streamable](r float64) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if r > 0 && r <= 1 {
+			c.MinUpvoteRatio = r
+		}
+	}
+}
+
+// WithStreamDomains skips posts that don't link to one of the domains, or to one of their subdomains.
+// Like with WithStreamRequireAuthor, skipped posts are still recorded as seen. Items that don't link anywhere,
+// such as comments, are never skipped. Empty domains are ignored.
+func WithStreamDomains[T Streamable](domains ...string) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		for _, domain := range domains {
+			if domain != "" {
+				c.Domains = append(c.Domains, domain)
+			}
+		}
+	}
+}
+
+// WithPostFlairFilter skips posts whose link flair text isn't flairText, ignoring case.
+// The filtering happens once the posts were fetched, so it doesn't let the stream keep up with busier
+// subreddits: every fetch still gets at most 100 posts, flair or not. Like with WithStreamRequireAuthor,
+// skipped posts are still recorded as seen. Items without a flair, such as comments, are never skipped.
+// If flairText is empty, it will not be set.
+func WithPostFlairFilter[T Streamable](flairText string) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if flairText != "" {
+			c.Filters = append(c.Filters, func(item T) bool {
+				return hasFlair(item, flairText)
+			})
+		}
+	}
+}
+
+// WithStreamFilter skips the items for which keep returns false, e.g. to only stream the posts of some authors
+// or with some keywords. keep is only called with new items. Like with WithStreamRequireAuthor, skipped items
+// are still recorded as seen, so they aren't considered again on the next fetches. When used more than once,
+// an item has to be kept by every filter. If keep is nil, it is ignored.
+func WithStreamFilter[T Streamable](keep func(T) bool) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if keep != nil {
+			c.Filters = append(c.Filters, keep)
+		}
+	}
+}
+
+// WithStreamBuffer sets the capacity of the channels returned by the stream, which are unbuffered by default.
+// A larger buffer takes more memory, but lets the stream keep fetching through a burst of items while the
+// consumer catches up, instead of falling behind the listing. If n is negative, it is ignored.
+func WithStreamBuffer[T Streamable](n int) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if n >= 0 {
+			c.Buffer = n
+		}
+	}
+}
+
+// WithStreamRetry retries a failed fetch up to maxRetries times before sending its error into the error channel,
+// waiting base before the first retry and twice as long before each of the next ones, up to a minute.
+// Once a retry succeeds, the stream goes on at its usual interval. Errors that stop the stream, such as
+// ErrSubredditNotFound, aren't retried. If either value is 0 or less, fetches aren't retried.
+func WithStreamRetry[T Streamable](maxRetries int, base time.Duration) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if maxRetries > 0 && base > 0 {
+			c.MaxRetries = maxRetries
+			c.RetryBase = base
+		}
+	}
+}
+
+// WithStreamContentHash skips items whose content was already streamed under another ID, e.g. reposts,
+// according to hash. If hash is nil, the item's ContentHash method is used, e.g. (*Post).ContentHash;
+// items that don't have one are never skipped. Like with WithStreamRequireAuthor, skipped items are still
+// recorded as seen. The stream remembers as many hashes as its high water mark does IDs by default.
+func WithStreamContentHash[T Streamable](hash func(T) string) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if hash == nil {
+			hash = func(item T) string {
+				if h, ok := any(item).(interface{ ContentHash() string }); ok {
+					return h.ContentHash()
+				}
+				return ""
+			}
+		}
+		c.ContentHash = hash
+		c.contentHashes = NewLRUDedupStore(defaultHighWaterMarkCapacity)
+	}
+}
+
+// WithAdaptiveInterval makes the stream adapt its interval to the traffic of the listing, between min and max.
+// Every time a fetch comes back with a full page, which means items were likely missed, the interval is halved;
+// every time it comes back with 10 items or fewer, it's doubled. The stream starts at the interval set with
+// WithStreamInterval, brought within the bounds. If min is 0 or less, or max is less than min, it is ignored.
+func WithAdaptiveInterval[T Streamable](min, max time.Duration) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if min > 0 && max >= min {
+			c.AdaptiveMin = min
+			c.AdaptiveMax = max
+		}
+	}
+}
+
+// WithStreamCircuitBreaker pauses fetching after failureThreshold consecutive failed fetches.
+// While the breaker is open no requests are made. Once cooldown has elapsed, a single probe fetch
+// is made: if it succeeds the stream resumes normally, otherwise the breaker opens again.
+// Every state change is sent into the error channel as a *CircuitBreakerEvent.
+// If either value is 0 or less, the breaker is not enabled.
+func WithStreamCircuitBreaker[T Streamable](failureThreshold int, cooldown time.Duration) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if failureThreshold > 0 && cooldown > 0 {
+			c.CircuitBreaker = newCircuitBreaker(failureThreshold, cooldown)
+		}
+	}
+}
+
+// WithStreamModerator only streams the actions taken by the given moderator.
+// The filtering is done by Reddit, via the "mod" parameter of the mod log.
+func WithStreamModerator(moderator string) StreamOpt[*ModAction] {
+	return func(c *streamConfig[*ModAction]) {
+		c.Moderator = moderator
+	}
+}
+
+// WithAutoMarkRead marks each message as read once it has been delivered on its channel, i.e. once the consumer
+// received it, or once it was buffered if the stream has a buffer. Marking is best-effort: a failure is sent on
+// the errors channel as a non-fatal *StreamError, and the stream carries on.
+// Only the inbox streams support it.
+func WithAutoMarkRead() StreamOpt[*Message] {
+	return func(c *streamConfig[*Message]) {
+		c.MarkRead = true
+	}
+}
+
+// WithStreamTime sets the time period that the top posts stream ranks posts over.
+// It must be one of: hour, day, week, month, year, all. Any other value will not be set and Reddit's default will be used.
+func WithStreamTime(t string) StreamOpt[*Post] {
+	return func(c *streamConfig[*Post]) {
+		switch t {
+		case "hour", "day", "week", "month", "year", "all":
+			c.Time = t
+		}
+	}
+}
+
+// WithStreamMaxConsecutiveEmpty stops the stream after v consecutive fetches that brought back nothing new,
+// either because they were empty or because every item in them was already seen.
+// Failed fetches don't count towards it, nor do they reset it. This is handy to end a one-off catch-up
+// once it has caught up, whereas WithStreamMaxRequests stops after a fixed number of fetches regardless.
+// If less than or equal to 0, it will not be set.
+func WithStreamMaxConsecutiveEmpty[T Streamable](v int) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if v > 0 {
+			c.MaxEmpty = v
+		}
+	}
+}
+
+// WithDropDetection calls fn whenever a fetched page is full and none of its items were seen before,
+// which means the listing moved on by at least a whole page since the previous fetch, and that items
+// created in between may never be streamed. It's a sign that the interval is too long for the traffic.
+// missed is a rough estimate of how many items were skipped, based on how fast the items in the page were
+// created, and it's always at least 1. The first fetch of a stream isn't checked, unless it's resuming.
+// fn is called from the stream's goroutine, so it should return quickly. If fn is nil, it will not be set.
+func WithDropDetection[T Streamable](fn func(missed int)) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if fn != nil {
+			c.DropDetection = fn
+		}
+	}
+}
+
+// WithStreamMetrics calls fn with the stream's running totals after each fetch, such as the number of
+// requests made and items emitted so far. fn is called from a separate goroutine, so a slow fn doesn't
+// hold up the stream, but if it's still busy when the next fetch completes, the stats in between are skipped
+// and it only gets the latest ones. Keep it fast, or hand the stats off, to see all of them.
+// The stream's channels are closed once fn has returned for the last time.
+// If fn is nil, it will not be set.
+func WithStreamMetrics[T Streamable](fn func(StreamStats)) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if fn != nil {
+			c.Metrics = fn
+		}
+	}
+}
+
+// WithStreamLogger makes the stream log what it's doing, such as every page it fetches along with how many
+// new items were in it, failed fetches and the retries that follow, and why it stopped.
+// level is one of "debug", "info", "warn" or "error", and kv holds alternating keys and values,
+// e.g. "subreddit", "golang", "items", 100, which maps directly onto most structured loggers.
+// The logger is called from the stream's goroutine, so it should return quickly.
+// By default, nothing is logged. If log is nil, it will not be set.
+func WithStreamLogger[T Streamable](log func(level, msg string, kv ...any)) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if log != nil {
+			c.Logger = log
+		}
+	}
+}
+
+// WithStreamErrorHandler hands the errors of the stream to fn instead of sending them into the errors channel,
+// so that a stream whose errors channel isn't read can't get stuck trying to send one. The errors channel then
+// never receives anything, and is only closed along with the items channel once the stream ends.
+// fn is called from the stream's goroutine, so it should return quickly. If fn is nil, it will not be set.
+func WithStreamErrorHandler[T Streamable](fn func(error)) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if fn != nil {
+			c.ErrorHandler = fn
+		}
+	}
+}
+
+// WithErrorChannelPolicy sets what the stream does with an error when its errors channel is full.
+// By default it blocks until the error is received, like with ErrorPolicyBlock. With the other policies,
+// the errors channel is buffered to the size set with WithStreamBuffer, or to 1 if it's smaller, and the
+// stream never waits on it. Together with WithStreamErrorHandler, the stream fails with ErrInvalidStreamConfig,
+// since the errors channel isn't used.
+func WithErrorChannelPolicy[T Streamable](policy ErrorPolicy) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		c.ErrorPolicy = policy
+	}
+}
+
+// WithStreamJitter adds a random delay of up to max to the interval between fetches,
+// so that many streams started at once don't all hit Reddit at the same time.
+// If the duration is 0 or less, it will not be set.
+func WithStreamJitter[T Streamable](max time.Duration) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if max > 0 {
+			c.Jitter = max
+		}
+	}
+}
+
+// WithStreamSampleRate only emits a random fraction of the new items, e.g. 0.1 for about 10% of them.
+// Items that aren't picked are still recorded as seen. The rate must be greater than 0 and
+// less than 1, otherwise it will not be set and every item will be emitted.
+func WithStreamSampleRate[T Streamable](rate float64) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if rate > 0 && rate < 1 {
+			c.SampleRate = rate
+		}
+	}
+}
+
+// WithStreamRandSource sets the source all of the stream's randomized behavior derives from, such as
+// jitter and sampling. This is mostly useful in tests, to make that behavior reproducible.
+// By default, a source seeded with the current time is used. If the source is nil, it will not be set.
+func WithStreamRandSource[T Streamable](src rand.Source) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if src != nil {
+			c.Rand = rand.New(src)
+		}
+	}
+}
+
+// WithStreamFirstPageLimit makes the very first fetch of the stream page through the listing until it has
+// up to n items, to backfill a larger history than a single fetch can get. Every fetch after that is a single page.
+// The extra pages don't count towards WithStreamMaxRequests. When resuming, e.g. with WithStartFromFullID,
+// paging stops as soon as it reaches an item that was already seen.
+// Only the Posts and Comments streams support it. Together with WithGetFunc or WithDumbLogic, the stream fails
+// with ErrInvalidStreamConfig.
+// If n is 100 (the size of a single page) or less, it will not be set.
+func WithStreamFirstPageLimit[T Streamable](n int) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if n > itemLimit {
+			c.FirstPageLimit = n
+		}
+	}
+}
+
+// WithStreamCompaction holds emitted items back for the given window, and then only emits the latest
+// state of each item seen during it, by full ID. This is meant for streams where the same item can be
+// emitted repeatedly as it changes, such as Reported, which re-emits an item whenever its report count changes.
+// Buffered items are flushed when the stream reaches its max requests.
+// If the duration is 0 or less, items are emitted as soon as they are fetched.
+func WithStreamCompaction[T Streamable](window time.Duration) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if window > 0 {
+			c.Compaction = window
+		}
+	}
+}
+
+// WithStreamClock sets the clock the stream uses for its ticks and for telling the time.
+// This is mostly useful in tests, with a FakeClock. If the clock is nil, it will not be set and the default will be used.
+func WithStreamClock[T Streamable](clock Clock) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if clock != nil {
+			c.Clock = clock
+		}
+	}
+}
+
+// WithStreamController attaches the controller to the stream, allowing it to be driven while it runs.
+func WithStreamController[T Streamable](controller *StreamController) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		c.Controller = controller
+	}
+}
+
+// WithStreamMinAge holds items back until they are at least d old, according to the stream's clock.
+// Items that are too young aren't recorded as seen, so they are checked again on subsequent fetches
+// and emitted once they're old enough. This is handy to avoid acting on items that are still being edited.
+// Items without a creation time are emitted right away.
+// If the duration is 0 or less, it will not be set.
+func WithStreamMinAge[T Streamable](d time.Duration) StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		if d > 0 {
+			c.MinAge = d
+		}
+	}
+}
+
+// WithDrainOnStop makes the stop function of the stream only stop it from fetching, while the items it already
+// fetched are still delivered, including the ones held back by WithStreamPrefetch or WithStreamCompaction.
+// Once they have all been, the channels are closed.
+// Keep receiving from the stream until its channels are closed after calling stop, or cancel its context to
+// give up on the remaining items, otherwise its goroutine will be stuck trying to deliver them. With streams that
+// emit into more than one channel, such as Reported, keep receiving from all of them.
+func WithDrainOnStop[T Streamable]() StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		c.DrainOnStop = true
+	}
+}
+
+// WithStreamPrefetch lets the stream make its next fetch while the items of the previous one are still
+// being received, instead of waiting until all of them have been. Items are still emitted in order
+// and deduplicated against everything fetched before them. At most one fetched page waits to be emitted
+// at a time, so a slow consumer still slows the stream down eventually.
+func WithStreamPrefetch[T Streamable]() StreamOpt[T] {
+	return func(c *streamConfig[T]) {
+		c.Prefetch = true
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/google/go-querystring/query"
 )
@@ -64,6 +65,28 @@ type SubredditRule struct {
 	Created         *Timestamp `json:"created_utc,omitempty"`
 }
 
+// RemovalReason is a structured reason moderators of the subreddit can give when removing a post or comment.
+type RemovalReason struct {
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+	// The message sent to the author when this reason is applied.
+	Message string `json:"message,omitempty"`
+}
+
+// FindRemovalReason returns the removal reason with the given ID among reasons, e.g. the ones from
+// (*SubredditService).RemovalReasons, or nil if it isn't one of them.
+func FindRemovalReason(reasons []*RemovalReason, id string) *RemovalReason {
+	if id == "" {
+		return nil
+	}
+	for _, reason := range reasons {
+		if reason.ID == id {
+			return reason
+		}
+	}
+	return nil
+}
+
 // SubredditRuleCreateRequest represents a request to add a subreddit rule.
 type SubredditRuleCreateRequest struct {
 	// One of: comment, link (i.e. post) or all (i.e. both).
@@ -869,6 +892,33 @@ func (s *SubredditService) Rules(ctx context.Context, subreddit string) ([]*Subr
 	return root.Rules, resp, nil
 }
 
+// RemovalReasons gets the removal reasons of the subreddit, in the order set by its moderators.
+func (s *SubredditService) RemovalReasons(ctx context.Context, subreddit string) ([]*RemovalReason, *Response, error) {
+	path := fmt.Sprintf("api/v1/%s/removal_reasons", subreddit)
+
+	req, err := s.client.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(struct {
+		Data  map[string]*RemovalReason `json:"data"`
+		Order []string                  `json:"order"`
+	})
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	reasons := make([]*RemovalReason, 0, len(root.Order))
+	for _, id := range root.Order {
+		if reason, ok := root.Data[id]; ok {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons, resp, nil
+}
+
 // CreateRule adds a rule to the subreddit.
 func (s *SubredditService) CreateRule(ctx context.Context, subreddit string, request *SubredditRuleCreateRequest) (*Response, error) {
 	err := request.validate()
@@ -1198,3 +1248,33 @@ func (s *SubredditService) PostRequirements(ctx context.Context, subreddit strin
 
 	return root, resp, nil
 }
+
+// AssignPostFlair assigns the flair template to the post, optionally overriding its text (if the template is editable).
+// It's a checked version of Flair.SelectForPost, for a post in the subreddit.
+// You have to be a moderator of the subreddit for this to work on posts that aren't yours.
+// If Reddit rejects the flair, e.g. because the template doesn't exist, a *JSONErrorResponse is returned.
+//
+// This is meant to be used as the action of an auto-flair bot consuming a stream:
+//
+//	posts, errs, stop := client.Stream.Posts(ctx, "subreddit")
+//	for post := range posts {
+//		if strings.Contains(post.Title, "[Question]") {
+//			_, err := client.Subreddit.AssignPostFlair(ctx, "subreddit", post.FullID, questionTemplateID, "")
+//		}
+//	}
+func (s *SubredditService) AssignPostFlair(ctx context.Context, subreddit, postFullID, templateID string, text string) (*Response, error) {
+	if subreddit == "" {
+		return nil, errors.New("subreddit: cannot be empty")
+	}
+	if !strings.HasPrefix(postFullID, kindPost+"_") {
+		return nil, fmt.Errorf("postFullID: %q is not the full ID of a post", postFullID)
+	}
+	if templateID == "" {
+		return nil, errors.New("templateID: cannot be empty")
+	}
+	if utf8.RuneCountInString(text) > 64 {
+		return nil, errors.New("text: cannot be longer than 64 characters")
+	}
+
+	return s.client.Flair.SelectForPost(ctx, postFullID, &FlairSelectRequest{ID: templateID, Text: text})
+}
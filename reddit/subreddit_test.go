@@ -2,6 +2,7 @@ package reddit
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -1163,6 +1164,40 @@ func TestSubredditService_Rules(t *testing.T) {
 	require.Equal(t, expectedRules, rules)
 }
 
+func TestSubredditService_RemovalReasons(t *testing.T) {
+	client, mux := setup(t)
+
+	blob, err := readFileContents("../testdata/subreddit/removal-reasons.json")
+	require.NoError(t, err)
+
+	mux.HandleFunc("/api/v1/testsubreddit/removal_reasons", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, blob)
+	})
+
+	reasons, _, err := client.Subreddit.RemovalReasons(ctx, "testsubreddit")
+	require.NoError(t, err)
+	require.Equal(t, []*RemovalReason{
+		{ID: "16a7b1lrsqkrz", Title: "Off-topic", Message: "Your post was removed because it's off-topic."},
+		{ID: "16a7ab3wnwi9c", Title: "Spam", Message: "Your post was removed because it's spam."},
+	}, reasons)
+
+	require.Equal(t, reasons[1], FindRemovalReason(reasons, "16a7ab3wnwi9c"))
+	require.Nil(t, FindRemovalReason(reasons, ""))
+	require.Nil(t, FindRemovalReason(reasons, "unknown"))
+
+	// the removal reason of a post is its text, not the ID of one of the subreddit's reasons
+	var post Post
+	err = json.Unmarshal([]byte(`{"name": "t3_post1", "removal_reason": "Your post was removed because it's spam."}`), &post)
+	require.NoError(t, err)
+	require.Equal(t, "Your post was removed because it's spam.", post.RemovalReason)
+
+	post = Post{}
+	err = json.Unmarshal([]byte(`{"name": "t3_post1", "removal_reason": null}`), &post)
+	require.NoError(t, err)
+	require.Empty(t, post.RemovalReason)
+}
+
 func TestSubredditService_CreateRule(t *testing.T) {
 	client, mux := setup(t)
 
@@ -1720,3 +1755,69 @@ func TestSubredditService_PostRequirements(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expectedSubredditPostRequirements, postRequirements)
 }
+
+func TestSubredditService_AssignPostFlair(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/api/selectflair", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+
+		form := url.Values{}
+		form.Set("api_type", "json")
+		form.Set("link", "t3_123")
+		form.Set("flair_template_id", "id123")
+		form.Set("text", "text123")
+
+		err := r.ParseForm()
+		require.NoError(t, err)
+		require.Equal(t, form, r.PostForm)
+	})
+
+	_, err := client.Subreddit.AssignPostFlair(ctx, "", "t3_123", "id123", "")
+	require.EqualError(t, err, "subreddit: cannot be empty")
+
+	_, err = client.Subreddit.AssignPostFlair(ctx, "test", "t1_123", "id123", "")
+	require.EqualError(t, err, `postFullID: "t1_123" is not the full ID of a post`)
+
+	_, err = client.Subreddit.AssignPostFlair(ctx, "test", "t3_123", "", "")
+	require.EqualError(t, err, "templateID: cannot be empty")
+
+	_, err = client.Subreddit.AssignPostFlair(ctx, "test", "t3_123", "id123", strings.Repeat("x", 65))
+	require.EqualError(t, err, "text: cannot be longer than 64 characters")
+
+	// the limit is on characters, not bytes
+	_, err = client.Subreddit.AssignPostFlair(ctx, "test", "t3_123", "id123", strings.Repeat("é", 65))
+	require.EqualError(t, err, "text: cannot be longer than 64 characters")
+
+	_, err = client.Subreddit.AssignPostFlair(ctx, "test", "t3_123", "id123", "text123")
+	require.NoError(t, err)
+}
+
+func TestSubredditService_AssignPostFlair_MultibyteText(t *testing.T) {
+	client, mux := setup(t)
+
+	text := strings.Repeat("🚀", 64)
+	mux.HandleFunc("/api/selectflair", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+
+		err := r.ParseForm()
+		require.NoError(t, err)
+		require.Equal(t, text, r.PostForm.Get("text"))
+	})
+
+	_, err := client.Subreddit.AssignPostFlair(ctx, "test", "t3_123", "id123", text)
+	require.NoError(t, err)
+}
+
+func TestSubredditService_AssignPostFlair_Error(t *testing.T) {
+	client, mux := setup(t)
+
+	mux.HandleFunc("/api/selectflair", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		fmt.Fprint(w, `{"json": {"errors": [["BAD_FLAIR_TARGET", "not a valid flair target", "link"]]}}`)
+	})
+
+	_, err := client.Subreddit.AssignPostFlair(ctx, "test", "t3_123", "id123", "")
+	require.IsType(t, &JSONErrorResponse{}, err)
+	require.Equal(t, "BAD_FLAIR_TARGET", err.(*JSONErrorResponse).JSON.Errors[0].Label)
+}
@@ -0,0 +1,47 @@
+package reddit
+
+import "sync"
+
+// Tee duplicates the items received from src into 2 channels, e.g. to both process the items of a stream and archive them.
+// Each channel buffers up to 100 items on its own, so a slow consumer on one side only holds up the other once its
+// buffer is full. Both channels are closed once src is, or once the returned function is called to stop duplicating.
+// Stopping doesn't stop src itself.
+func Tee[T any](src <-chan T) (<-chan T, <-chan T, func()) {
+	a := make(chan T, itemLimit)
+	b := make(chan T, itemLimit)
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer close(a)
+		defer close(b)
+
+		for {
+			select {
+			case <-done:
+				return
+			case item, ok := <-src:
+				if !ok {
+					return
+				}
+				for _, ch := range [...]chan T{a, b} {
+					select {
+					case ch <- item:
+					case <-done:
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+	return a, b, stop
+}
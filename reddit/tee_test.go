@@ -0,0 +1,104 @@
+package reddit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTee(t *testing.T) {
+	client, mux := setup(t)
+
+	var counter int
+	mux.HandleFunc("/r/testsubreddit/new", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		defer func() { counter++ }()
+
+		fmt.Fprintf(w, `{
+			"kind": "Listing",
+			"data": {
+				"children": [
+					{"kind": "t3", "data": {"name": "t3_post%d"}},
+					{"kind": "t3", "data": {"name": "t3_post%d"}}
+				]
+			}
+		}`, counter*2+2, counter*2+1)
+	})
+
+	posts, _, stop := client.Stream.Posts(context.Background(), "testsubreddit",
+		WithStreamInterval[*Post](time.Millisecond*10),
+		WithStreamMaxRequests[*Post](5),
+	)
+	defer stop()
+
+	a, b, stopTee := Tee(posts)
+	defer stopTee()
+
+	var wg sync.WaitGroup
+	var fromA, fromB []string
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for post := range a {
+			fromA = append(fromA, post.FullID)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		// a slow consumer doesn't lose anything
+		for post := range b {
+			time.Sleep(time.Millisecond)
+			fromB = append(fromB, post.FullID)
+		}
+	}()
+	wg.Wait()
+
+	var expected []string
+	for i := 1; i <= 10; i += 2 {
+		expected = append(expected, fmt.Sprintf("t3_post%d", i+1), fmt.Sprintf("t3_post%d", i))
+	}
+	require.Equal(t, expected, fromA)
+	require.Equal(t, expected, fromB)
+}
+
+func TestTee_SlowBranch(t *testing.T) {
+	src := make(chan int)
+	a, b, stop := Tee(src)
+
+	// nothing reads from b, but a keeps getting items until b's buffer is full
+	go func() {
+		for i := 0; i < itemLimit; i++ {
+			src <- i
+		}
+	}()
+	for i := 0; i < itemLimit; i++ {
+		select {
+		case v := <-a:
+			require.Equal(t, i, v)
+		case <-time.After(time.Second):
+			t.Fatal("a got stalled by b")
+		}
+	}
+
+	// stopping doesn't block, even though b is full and an item is waiting to be sent
+	go func() {
+		select {
+		case src <- itemLimit:
+		case <-time.After(time.Second):
+		}
+	}()
+	time.Sleep(time.Millisecond * 10)
+	stop()
+	stop()
+
+	var fromB []int
+	for v := range b {
+		fromB = append(fromB, v)
+	}
+	require.Len(t, fromB, itemLimit)
+}
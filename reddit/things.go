@@ -1,8 +1,13 @@
 package reddit
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 )
 
 const (
@@ -440,6 +445,24 @@ type Comment struct {
 	NumReports int `json:"num_reports"`
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Fields that are missing are looked up under the other names Reddit has sent them under.
+func (c *Comment) UnmarshalJSON(b []byte) error {
+	type comment Comment
+	v := struct {
+		*comment
+		fieldFallbacks
+		// shadows the field of the comment, to tell whether it was sent at all
+		NSFW *bool `json:"over_18"`
+	}{comment: (*comment)(c)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	v.fieldFallbacks.apply(&c.Created, &c.AuthorID, &c.NSFW, v.NSFW)
+	return nil
+}
+
 func (p *Comment) GetFullID() string {
 	return p.FullID
 }
@@ -447,6 +470,54 @@ func (p *Comment) GetCreated() *Timestamp {
 	return p.Created
 }
 
+// HasAuthor reports whether the comment came back with an author.
+// Listings occasionally contain shadow-removed comments whose author field is missing entirely.
+func (c *Comment) HasAuthor() bool {
+	return c.Author != ""
+}
+
+// IsScoreHidden reports whether the comment's score is currently hidden. Subreddits can hide the scores
+// of new comments for a while, in which case Score is meaningless until it's revealed.
+func (c *Comment) IsScoreHidden() bool {
+	if c == nil {
+		return false
+	}
+	return c.ScoreHidden
+}
+
+// IsControversial reports whether Reddit marked the comment as controversial, meaning it got about as many
+// upvotes as downvotes, which its score alone doesn't tell.
+func (c *Comment) IsControversial() bool {
+	if c == nil {
+		return false
+	}
+	return c.Controversiality > 0
+}
+
+// GetScore returns the score of the comment. Check IsScoreHidden before relying on it.
+func (c *Comment) GetScore() int {
+	if c == nil {
+		return 0
+	}
+	return c.Score
+}
+
+// ContentHash returns a hash of what the comment says, where and by whom: its author, subreddit, post, parent and body.
+// Fields that change over the life of the comment, such as its score or when it was edited, are left out,
+// as is its ID, so that the same comment posted twice hashes the same.
+func (c *Comment) ContentHash() string {
+	return contentHash(c.Author, c.SubredditName, c.PostID, c.ParentID, c.Body)
+}
+
+// Age returns how long ago the comment was created, relative to now.
+// If the comment has no creation time, 0 is returned.
+func (c *Comment) Age(now time.Time) time.Duration {
+	if c.Created == nil {
+		return 0
+	}
+	return now.Sub(c.Created.Time)
+}
+
 // HasMore determines whether the comment has more replies to load in its reply tree.
 func (c *Comment) HasMore() bool {
 	return c.Replies.More != nil && len(c.Replies.More.Children) > 0
@@ -537,6 +608,8 @@ type Post struct {
 
 	Permalink string `json:"permalink,omitempty"`
 	URL       string `json:"url,omitempty"`
+	// The domain the post links to, e.g. github.com, or self.<subreddit> for self posts.
+	Domain string `json:"domain,omitempty"`
 
 	Title string `json:"title,omitempty"`
 	Body  string `json:"selftext,omitempty"`
@@ -563,16 +636,24 @@ type Post struct {
 	Author   string `json:"author,omitempty"`
 	AuthorID string `json:"author_fullname,omitempty"`
 
-	Spoiler    bool `json:"spoiler"`
-	Locked     bool `json:"locked"`
-	NSFW       bool `json:"over_18"`
-	IsSelfPost bool `json:"is_self"`
-	Saved      bool `json:"saved"`
-	Stickied   bool `json:"stickied"`
+	Spoiler     bool `json:"spoiler"`
+	Locked      bool `json:"locked"`
+	NSFW        bool `json:"over_18"`
+	IsSelfPost  bool `json:"is_self"`
+	Saved       bool `json:"saved"`
+	Stickied    bool `json:"stickied"`
+	ContestMode bool `json:"contest_mode"`
+
+	// Reddit's brand safety categorization of the post, e.g. all_ads, house_only, promo_adult_nsfw.
+	WhitelistStatus string `json:"whitelist_status,omitempty"`
 
 	// Moderation
 	NumReports    int  `json:"num_reports"`
 	IgnoreReports bool `json:"ignore_reports"`
+	// Why the post is no longer up, if it isn't, e.g. moderator, deleted, reddit.
+	RemovedByCategory string `json:"removed_by_category,omitempty"`
+	// The text of the removal reason a moderator gave for removing the post, if any.
+	RemovalReason string `json:"removal_reason,omitempty"`
 
 	// Content
 	IsVideo         bool      `json:"is_video"`
@@ -583,6 +664,60 @@ type Post struct {
 	PostHint        string    `json:"post_hint"`
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Fields that are missing are looked up under the other names Reddit has sent them under.
+func (p *Post) UnmarshalJSON(b []byte) error {
+	type post Post
+	v := struct {
+		*post
+		fieldFallbacks
+		CommentCount *int    `json:"comment_count"`
+		FlairText    *string `json:"flair_text"`
+		// shadow the fields of the post, to tell whether they were sent at all
+		NSFW        *bool `json:"over_18"`
+		NumComments *int  `json:"num_comments"`
+	}{post: (*post)(p)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	v.fieldFallbacks.apply(&p.Created, &p.AuthorID, &p.NSFW, v.NSFW)
+	switch {
+	case v.NumComments != nil:
+		p.NumberOfComments = *v.NumComments
+	case v.CommentCount != nil:
+		p.NumberOfComments = *v.CommentCount
+	}
+	if p.LinkFlairText == "" && v.FlairText != nil {
+		p.LinkFlairText = *v.FlairText
+	}
+	return nil
+}
+
+// fieldFallbacks holds the other names Reddit has sent the fields of both posts and comments under.
+// They're decoded along with the item, and only used for the fields the item came back without.
+type fieldFallbacks struct {
+	Created  *Timestamp `json:"created"`
+	AuthorID *string    `json:"author_id"`
+	Over18   *bool      `json:"over18"`
+}
+
+// apply fills in the fields of the item that are missing. sentNSFW is the NSFW flag sent under its current name, if any.
+func (f fieldFallbacks) apply(created **Timestamp, authorID *string, nsfw *bool, sentNSFW *bool) {
+	if *created == nil && f.Created != nil {
+		*created = f.Created
+	}
+	if *authorID == "" && f.AuthorID != nil {
+		*authorID = *f.AuthorID
+	}
+	switch {
+	case sentNSFW != nil:
+		*nsfw = *sentNSFW
+	case f.Over18 != nil:
+		*nsfw = *f.Over18
+	}
+}
+
 func (p *Post) GetFullID() string {
 	return p.FullID
 }
@@ -590,6 +725,114 @@ func (p *Post) GetCreated() *Timestamp {
 	return p.Created
 }
 
+// HasAuthor reports whether the post came back with an author.
+// Listings occasionally contain shadow-removed posts whose author field is missing entirely.
+func (p *Post) HasAuthor() bool {
+	return p.Author != ""
+}
+
+// Age returns how long ago the post was created, relative to now.
+// If the post has no creation time, 0 is returned.
+func (p *Post) Age(now time.Time) time.Duration {
+	if p.Created == nil {
+		return 0
+	}
+	return now.Sub(p.Created.Time)
+}
+
+// GetScore returns the score of the post.
+func (p *Post) GetScore() int {
+	if p == nil {
+		return 0
+	}
+	return p.Score
+}
+
+// GetSubredditSubscribers returns the number of subscribers of the subreddit the post was submitted to.
+func (p *Post) GetSubredditSubscribers() int {
+	if p == nil {
+		return 0
+	}
+	return p.SubredditSubscribers
+}
+
+// GetSubredditNamePrefixed returns the name of the subreddit the post was submitted to, e.g. "r/golang".
+func (p *Post) GetSubredditNamePrefixed() string {
+	if p == nil {
+		return ""
+	}
+	return p.SubredditNamePrefixed
+}
+
+// ContentHash returns a hash of what the post is, where and by whom: its author, subreddit, title, body and URL.
+// Fields that change over the life of the post, such as its score, number of comments or when it was edited,
+// are left out, as is its ID, so that reposts of the same content hash the same.
+func (p *Post) ContentHash() string {
+	return contentHash(p.Author, p.SubredditName, p.Title, p.Body, p.URL)
+}
+
+// contentHash returns the hex-encoded SHA-256 of the fields. Each one is prefixed with its length,
+// so that moving text from one field to the next changes the hash.
+func contentHash(fields ...string) string {
+	h := sha256.New()
+	for _, field := range fields {
+		fmt.Fprintf(h, "%d:%s", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// GetUpvoteRatio returns the ratio of upvotes to total votes of the post, from 0 to 1.
+// It's 0 when Reddit didn't include it.
+func (p *Post) GetUpvoteRatio() float32 {
+	if p == nil {
+		return 0
+	}
+	return p.UpvoteRatio
+}
+
+// SortPostsByRatio sorts the posts by upvote ratio, highest first. Posts with the same ratio keep their order.
+func SortPostsByRatio(posts []*Post) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].GetUpvoteRatio() > posts[j].GetUpvoteRatio()
+	})
+}
+
+// LinksTo reports whether the post links to the domain or to one of its subdomains, ignoring case.
+func (p *Post) LinksTo(domain string) bool {
+	if p == nil || domain == "" {
+		return false
+	}
+	d := strings.ToLower(p.Domain)
+	domain = strings.ToLower(domain)
+	return d == domain || strings.HasSuffix(d, "."+domain)
+}
+
+// IsNSFW reports whether the post is marked as NSFW (over 18).
+func (p *Post) IsNSFW() bool {
+	if p == nil {
+		return false
+	}
+	return p.NSFW
+}
+
+// IsSpoiler reports whether the post is marked as a spoiler.
+func (p *Post) IsSpoiler() bool {
+	if p == nil {
+		return false
+	}
+	return p.Spoiler
+}
+
+// BrandSafety returns Reddit's brand safety categorization of the post (its whitelist status),
+// e.g. all_ads, house_only, promo_adult_nsfw. Posts in quarantined or private subreddits usually don't have one,
+// in which case an empty string is returned.
+func (p *Post) BrandSafety() string {
+	if p == nil {
+		return ""
+	}
+	return p.WhitelistStatus
+}
+
 type PostMedia struct {
 	RedditVideo struct {
 		BitrateKbps       int    `json:"bitrate_kbps"`